@@ -1,9 +1,14 @@
 package apexif
 
 import (
+	"bytes"
+	"io"
+
 	"github.com/abrander/apexif/fileformats"
 
+	"github.com/abrander/apexif/fileformats/avif"
 	"github.com/abrander/apexif/fileformats/cr2"
+	"github.com/abrander/apexif/fileformats/cr3"
 	"github.com/abrander/apexif/fileformats/crw"
 	"github.com/abrander/apexif/fileformats/heic"
 	"github.com/abrander/apexif/fileformats/jpeg"
@@ -17,18 +22,28 @@ import (
 // If the file format is not recognized, ErrImageNotRecognized
 // is returned.
 func Identify(data []byte) (fileformats.FileType, error) {
-	try := []fileformats.Identifier{
-		jpeg.Identify,
-		png.Identify,
-		heic.Identify,
-		webp.Identify,
-		cr2.Identify,
-		crw.Identify,
-		tif.Identify,
+	return IdentifyReaderAt(bytes.NewReader(data), int64(len(data)))
+}
+
+// IdentifyReaderAt works like Identify, but reads size bytes from r
+// instead of requiring the whole file in memory. Only the parts of
+// the file needed are read, and r must stay readable for as long as
+// the returned FileType is used.
+func IdentifyReaderAt(r io.ReaderAt, size int64) (fileformats.FileType, error) {
+	try := []fileformats.ReaderAtIdentifier{
+		jpeg.IdentifyReaderAt,
+		png.IdentifyReaderAt,
+		heic.IdentifyReaderAt,
+		cr3.IdentifyReaderAt,
+		avif.IdentifyReaderAt,
+		webp.IdentifyReaderAt,
+		cr2.IdentifyReaderAt,
+		crw.IdentifyReaderAt,
+		tif.IdentifyReaderAt,
 	}
 
 	for _, recognizer := range try {
-		fileType, err := recognizer(data)
+		fileType, err := recognizer(r, size)
 		if err == nil {
 			return fileType, nil
 		}
@@ -1,22 +1,28 @@
 package bmff
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/abrander/apexif/fileformats"
 )
 
 type Bmff struct {
-	bytes     []byte
-	iinfItems []item
-}
-
-type item struct {
-	tag    string
-	offset uint32
-	length uint32
+	r     *io.SectionReader
+	root  Box
+	ftyp  Ftyp
+	items []*item
+	idat  *Box
+
+	// itemsByID indexes items by ID. items keeps them in the order
+	// they were first seen.
+	itemsByID map[uint32]*item
+
+	primary    uint32
+	properties []any
 }
 
 var Debug = false
@@ -48,298 +54,128 @@ func parseBox(data []byte) (length uint64, boxType string) {
 	return
 }
 
-const fullboxSize = 12
-
-func parseFullbox(data []byte) (length uint64, boxType string, version uint8, flags uint32) {
-	if len(data) < fullboxSize {
-		return
-	}
-
-	length, boxType = parseBox(data)
-
-	version = uint8(data[8])
-
-	flags = uint32(data[9])
-	flags = flags<<8 | uint32(data[10])
-	flags = flags<<8 | uint32(data[11])
-
-	return
+func Parse(data []byte) (*Bmff, error) {
+	return ParseReaderAt(bytes.NewReader(data), int64(len(data)))
 }
 
-func Parse(data []byte) (*Bmff, error) {
-	if len(data) < 12 {
+// ParseReaderAt parses size bytes from r as an ISOBMFF container. Box
+// headers are read while parsing, payloads only for the boxes needed
+// for item lookup.
+func ParseReaderAt(r io.ReaderAt, size int64) (*Bmff, error) {
+	b := &Bmff{r: io.NewSectionReader(r, 0, size)}
+
+	header, err := fileformats.ReadAt(b.r, 0, 12)
+	if err != nil {
 		return nil, returnErr(fileformats.ErrImageNotRecognized)
 	}
 
-	if string(data[4:8]) != "ftyp" {
+	if string(header[4:8]) != "ftyp" {
 		return nil, returnErr(fileformats.ErrImageNotRecognized)
 	}
 
-	b := &Bmff{data, nil}
-
-	for len(data) > boxSize {
-		length, err := b.parseBox(data[0:])
-		if err != nil {
+	// A malformed box deep in the file should not hide the metadata,
+	// so the boxes read before the error are used.
+	b.root.Children, err = ReadBoxes(b.r, 0, size)
+	if err != nil {
+		if len(b.root.Children) == 0 {
 			return nil, err
 		}
 
-		if length == 0 {
-			break
-		}
-
-		if len(data) < int(length) {
-			return nil, returnErr(fileformats.ErrImageNotRecognized)
-		}
-
-		data = data[length:]
+		debugf("ParseReaderAt: %s", err)
 	}
 
-	return b, nil
-}
+	for _, box := range b.root.Children {
+		switch box.Type {
+		case "ftyp":
+			data, err := box.Payload()
+			if err != nil {
+				return nil, returnErr(fileformats.ErrImageNotRecognized)
+			}
 
-func (b *Bmff) Iloc(tag string) []byte {
-	const WTF = 10
+			b.ftyp, err = parseFtypPayload(data)
+			if err != nil {
+				return nil, err
+			}
 
-	for _, item := range b.iinfItems {
-		if item.tag == tag {
-			return b.bytes[item.offset+WTF : item.offset+WTF+item.length]
+		case "meta":
+			err = b.parseMeta(box)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	return nil
+	return b, nil
 }
 
-func (b *Bmff) parseBox(data []byte) (length uint64, err error) {
-	if len(data) < boxSize {
-		err = returnErr(fileformats.ErrImageNotRecognized)
-
-		return
-	}
-
-	length, boxType := parseBox(data)
-	start := boxSize
-
-	if len(data) < int(length) {
-		length = 0
-		err = returnErr(fileformats.ErrImageNotRecognized)
-
-		return
-	}
-
-	switch length {
-	case 0:
-		// Box extends to end of file.
-		length = uint64(len(data))
-
-	case 1:
-		// 64-bit length
-		length = binary.BigEndian.Uint64(data[8:16])
-		start += 8
-	}
-
-	debugf("parseBox: type:%s length:%d", boxType, length)
-
-	if length > uint64(len(data)) {
-		length = 0
-		err = returnErr(fileformats.ErrImageNotRecognized)
-
-		return
-	}
-
-	if start >= int(length) {
-		err = returnErr(fileformats.ErrImageNotRecognized)
-
-		return
-	}
-
-	switch boxType {
-	case "ftyp":
-		length, err = parseFtyp(data[start:length])
-		return length + boxSize, err
-
-	case "meta":
-		length, err = b.parseMeta(data[start:length])
-		return length + boxSize, err
-	}
-
-	return
+// Boxes returns the top level boxes.
+func (b *Bmff) Boxes() []*Box {
+	return b.root.Children
 }
 
-func parseFtyp(data []byte) (length uint64, err error) {
-	if len(data) < boxSize {
-		err = returnErr(fileformats.ErrImageNotRecognized)
-
-		return
-	}
-
-	length = uint64(len(data))
-
-	return
+// Walk calls fn for each box in the container, depth first. See
+// Box.Walk.
+func (b *Bmff) Walk(fn func(path string, box *Box) error) error {
+	return b.root.Walk(fn)
 }
 
-func parseInfe(data []byte) (tag string) {
-	if len(data) < 12 {
-		return
-	}
-
-	tag = string(data[8:12])
-
-	return
+// Find returns the first box matching path, a slash separated list of
+// box types like "moov/trak/mdia/mdhd".
+func (b *Bmff) Find(path string) (*Box, error) {
+	return b.root.Find(path)
 }
 
-func (b *Bmff) parseIinf0(data []byte) {
-	if len(data) < 8 {
-		return
-	}
-
-	items := binary.BigEndian.Uint16(data[0:2])
-
-	debugf("parseIinf0 items:%d", items)
-
-	data = data[2:]
-
-	if b.iinfItems == nil {
-		b.iinfItems = make([]item, items)
-	}
-
-	if len(b.iinfItems) != int(items) {
-		debugf("parseIinf0: item count mismatch: %d != %d", len(b.iinfItems), items)
-
-		return
-	}
-
-	for i := range b.iinfItems {
-		length, tag := parseBox(data)
-		if tag == "" {
-			debugf("parseIinf0: pos:%d No tag", i)
-
-			return
-		}
-
-		if len(data) < 12+2 {
-			debugf("parseIinf0 pos:%d: Too short", i)
-
-			return
-		}
-
-		item := binary.BigEndian.Uint16(data[12:])
-
-		if item < 1 || item > uint16(len(b.iinfItems)) {
-			debugf("parseIinf0 pos:%d: item:%d Invalid item number", i, item)
-
-			continue
-		}
-
-		if length > uint64(len(data)) {
-			debugf("parseIinf0 pos:%d: infe: length:%d > len(data):%d", i, length, len(data))
-
-			return
-		}
-
-		var infe string
-		switch tag {
-		case "infe":
-			infe = parseInfe(data[boxSize : boxSize+length])
-			b.iinfItems[i].tag = infe
-		}
-
-		debugf("parseIinf0 pos:%d item:%d tag:%s infe:%s", i, item, tag, infe)
-
-		data = data[length:]
-	}
+// Ftyp returns the file type box.
+func (b *Bmff) Ftyp() Ftyp {
+	return b.ftyp
 }
 
-func (b *Bmff) parseIloc1(data []byte) {
-	if len(data) < 8 {
-		debugf("parseIloc1: Too short")
-		return
-	}
-
-	items := binary.BigEndian.Uint16(data[2:4])
-
-	debugf("parseIloc1: items:%d", items)
-
-	if b.iinfItems == nil {
-		b.iinfItems = make([]item, items)
-	}
-
-	if len(data) < 16*int(items) {
-		debugf("parseIloc1: Too short.")
-
-		return
-	}
-
-	if len(b.iinfItems) != int(items) {
-		debugf("parseIloc1: item count mismatch: %d != %d", len(b.iinfItems), items)
-
-		return
-	}
-
-	data = data[4:]
-
-	for i := range b.iinfItems {
-		item := binary.BigEndian.Uint16(data[0:2])
-		offset := binary.BigEndian.Uint32(data[8:12])
-		length := binary.BigEndian.Uint32(data[12:16])
-
-		if item < 1 || item > uint16(len(b.iinfItems)) {
-			debugf("parseIloc1 item:%d: Invalid item number: %d", i, item)
-
-			continue
-		}
-
-		b.iinfItems[item-1].offset = offset
-		b.iinfItems[item-1].length = length
-
-		debugf("parseIloc1 pos:%d item:%d: tag:%s, offset:0x%x, length:0x%x", i, item, b.iinfItems[i].tag, offset, length)
-
-		if len(data) < 16 {
-			return
+// Iloc returns the data of the first item of the given type, or nil
+// if no such item is found or the data can't be read.
+func (b *Bmff) Iloc(tag string) []byte {
+	for _, item := range b.items {
+		if item.itemType == tag {
+			data, err := b.itemData(item)
+			if err != nil {
+				return nil
+			}
+
+			return data
 		}
-
-		data = data[16:]
-	}
-}
-
-func (b *Bmff) parseMeta(data []byte) (length uint64, err error) {
-	if len(data) < 8 {
-		err = returnErr(fileformats.ErrImageNotRecognized)
-
-		return
 	}
 
-	read := uint64(4)
-	data = data[4:]
+	return nil
+}
 
-	for len(data) > fullboxSize {
-		length, tag, version, tags := parseFullbox(data)
+func (b *Bmff) parseMeta(meta *Box) error {
+	for _, box := range meta.Children {
+		debugf("parseMeta tag:%s length:%d version:%d flags:%x", box.Type, box.Size, box.Version, box.Flags)
 
-		if length < fullboxSize || length > uint64(len(data)) {
-			err = returnErr(fileformats.ErrImageNotRecognized)
+		switch box.Type {
+		case "iinf":
+			b.parseIinf(box)
 
-			break
-		}
+		case "iloc":
+			data, err := box.Payload()
+			if err != nil {
+				return returnErr(fileformats.ErrImageNotRecognized)
+			}
 
-		debugf("parseMeta tag:%s length:%d version:%d flags:%x", tag, length, version, tags)
+			b.parseIloc(box.Version, data)
 
-		switch {
-		case tag == "iinf" && version == 0:
-			b.parseIinf0(data[fullboxSize:length])
+		case "pitm":
+			b.parsePitm(box)
 
-		case tag == "iloc" && version == 1:
-			b.parseIloc1(data[fullboxSize:length])
-		}
+		case "iprp":
+			b.parseIprp(box)
 
-		if uint64(len(data))+1 < length {
-			err = returnErr(fileformats.ErrImageNotRecognized)
+		case "iref":
+			b.parseIref(box)
 
-			break
+		case "idat":
+			b.idat = box
 		}
-
-		read += length
-
-		data = data[length:]
 	}
 
-	return read, err
+	return nil
 }
@@ -0,0 +1,255 @@
+package bmff
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/abrander/apexif/fileformats"
+)
+
+// Box is a type representing a box and, for container boxes, its
+// children. The payload is not read until requested.
+type Box struct {
+	BoxHeader
+
+	// FullBox is true for boxes carrying a version and flags.
+	FullBox bool
+	Version uint8
+	Flags   uint32
+
+	// Children holds the boxes contained in this box, if the box is a
+	// known container box.
+	Children []*Box
+
+	r *io.SectionReader
+}
+
+// ErrBoxNotFound is returned by Find if no box matches the path.
+var ErrBoxNotFound = errors.New("box not found")
+
+// container describes how to find the children of a container box.
+type container struct {
+	// skip is the number of bytes between the header, including the
+	// version and flags, and the first child box.
+	skip func(version uint8) int64
+}
+
+func skip(n int64) func(uint8) int64 {
+	return func(uint8) int64 { return n }
+}
+
+// containers lists the container boxes we know how to descend into.
+var containers = map[string]container{
+	"moov": {skip(0)},
+	"trak": {skip(0)},
+	"edts": {skip(0)},
+	"mdia": {skip(0)},
+	"minf": {skip(0)},
+	"dinf": {skip(0)},
+	"stbl": {skip(0)},
+	"mvex": {skip(0)},
+	"moof": {skip(0)},
+	"traf": {skip(0)},
+	"mfra": {skip(0)},
+	"udta": {skip(0)},
+	"tref": {skip(0)},
+	"sinf": {skip(0)},
+	"schi": {skip(0)},
+	"iprp": {skip(0)},
+	"ipco": {skip(0)},
+	"grpl": {skip(0)},
+	"meta": {skip(0)},
+	"iref": {skip(0)},
+	"dref": {skip(4)},
+	"stsd": {skip(4)},
+	"iinf": {func(version uint8) int64 {
+		if version == 0 {
+			return 2
+		}
+
+		return 4
+	}},
+}
+
+// fullBoxes lists the boxes carrying a version and flags.
+var fullBoxes = map[string]bool{
+	"meta": true, "iinf": true, "infe": true, "iloc": true, "pitm": true,
+	"iref": true, "ipma": true, "ispe": true, "pixi": true, "auxC": true,
+	"hdlr": true, "dref": true, "stsd": true, "mvhd": true, "tkhd": true,
+	"mdhd": true, "vmhd": true, "smhd": true, "nmhd": true, "elst": true,
+	"stts": true, "ctts": true, "stsc": true, "stsz": true, "stz2": true,
+	"stco": true, "co64": true, "stss": true, "sdtp": true, "mehd": true,
+	"trex": true, "mfhd": true, "tfhd": true, "tfdt": true, "trun": true,
+	"url ": true, "urn ": true, "iods": true, "schm": true,
+}
+
+// maxDepth is the maximum nesting depth of container boxes. Real files
+// nest less than ten levels deep.
+const maxDepth = 32
+
+// ReadBoxes reads the boxes found between offset and end in r,
+// descending into known container boxes. On error the boxes that could
+// be read are returned along with the error. A malformed box
+// ends the list, while a malformed child leaves its siblings intact.
+func ReadBoxes(r *io.SectionReader, offset int64, end int64) ([]*Box, error) {
+	return readBoxes(r, offset, end, 0)
+}
+
+func readBoxes(r *io.SectionReader, offset int64, end int64, depth int) ([]*Box, error) {
+	if depth > maxDepth {
+		return nil, returnErr(fileformats.ErrImageNotRecognized)
+	}
+
+	headers, err := ReadBoxHeaders(r, offset, end)
+
+	boxes := make([]*Box, 0, len(headers))
+
+	for _, h := range headers {
+		b, boxErr := readBox(r, h, depth)
+		if b != nil {
+			boxes = append(boxes, b)
+		}
+
+		if err == nil {
+			err = boxErr
+		}
+	}
+
+	return boxes, err
+}
+
+// readBox reads the box described by h. If reading the children of a
+// container box fails, the box is returned with the children read
+// before the error.
+func readBox(r *io.SectionReader, h BoxHeader, depth int) (*Box, error) {
+	b := &Box{
+		BoxHeader: h,
+		FullBox:   fullBoxes[h.Type],
+		r:         r,
+	}
+
+	start := h.PayloadOffset()
+
+	if h.Type == "meta" {
+		b.FullBox = !plainMeta(r, h)
+	}
+
+	if b.FullBox {
+		buf, err := fileformats.ReadAt(r, start, 4)
+		if err != nil || h.PayloadSize() < 4 {
+			return nil, returnErr(fileformats.ErrImageNotRecognized)
+		}
+
+		b.Version = buf[0]
+		b.Flags = uint32(buf[1])<<16 | uint32(buf[2])<<8 | uint32(buf[3])
+		start += 4
+	}
+
+	c, found := containers[h.Type]
+	if !found {
+		return b, nil
+	}
+
+	start += c.skip(b.Version)
+
+	end := h.Offset + h.Size
+	if start > end {
+		return nil, returnErr(fileformats.ErrImageNotRecognized)
+	}
+
+	var err error
+	b.Children, err = readBoxes(r, start, end, depth+1)
+
+	return b, err
+}
+
+// plainMeta returns true if h is a meta box written as a plain box, as
+// QuickTime does in udta. The payload then starts with the hdlr box
+// rather than the version and flags.
+func plainMeta(r *io.SectionReader, h BoxHeader) bool {
+	if h.PayloadSize() < 8 {
+		return false
+	}
+
+	buf, err := fileformats.ReadAt(r, h.PayloadOffset(), 8)
+
+	return err == nil && string(buf[4:8]) == "hdlr"
+}
+
+// Payload reads the payload of the box. For full boxes the version
+// and flags are not included.
+func (b *Box) Payload() ([]byte, error) {
+	offset, size := b.PayloadOffset(), b.PayloadSize()
+
+	if b.FullBox {
+		offset += 4
+		size -= 4
+	}
+
+	return fileformats.ReadAt(b.r, offset, size)
+}
+
+// ReadChildren reads the payload of the box as a list of boxes. This
+// is useful for boxes not known to be containers by this package, like
+// vendor specific uuid boxes.
+func (b *Box) ReadChildren() ([]*Box, error) {
+	offset := b.PayloadOffset()
+	if b.FullBox {
+		offset += 4
+	}
+
+	return ReadBoxes(b.r, offset, b.Offset+b.Size)
+}
+
+// Walk calls fn for each box below b, depth first. path is the slash
+// separated list of box types leading to the box, relative to b. If
+// fn returns an error, walking stops and the error is returned.
+func (b *Box) Walk(fn func(path string, box *Box) error) error {
+	return walk(b.Children, "", fn)
+}
+
+func walk(boxes []*Box, prefix string, fn func(path string, box *Box) error) error {
+	for _, box := range boxes {
+		path := prefix + box.Type
+
+		err := fn(path, box)
+		if err != nil {
+			return err
+		}
+
+		err = walk(box.Children, path+"/", fn)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Find returns the first box below b matching path, a slash separated
+// list of box types like "moov/trak/mdia/mdhd".
+func (b *Box) Find(path string) (*Box, error) {
+	return find(b.Children, path)
+}
+
+func find(boxes []*Box, path string) (*Box, error) {
+	boxType, rest, nested := strings.Cut(path, "/")
+
+	for _, box := range boxes {
+		if box.Type != boxType {
+			continue
+		}
+
+		if !nested {
+			return box, nil
+		}
+
+		found, err := find(box.Children, rest)
+		if err == nil {
+			return found, nil
+		}
+	}
+
+	return nil, ErrBoxNotFound
+}
@@ -0,0 +1,109 @@
+package bmff
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/abrander/apexif/fileformats"
+)
+
+// BoxHeader is a type representing the header of a box.
+type BoxHeader struct {
+	// Type is the four character box type.
+	Type string
+
+	// UserType is the 16 byte extended type of "uuid" boxes.
+	UserType string
+
+	// Offset is the offset of the box in the reader.
+	Offset int64
+
+	// HeaderSize is the size of the header, including the large size
+	// and user type if present.
+	HeaderSize int64
+
+	// Size is the total size of the box including the header.
+	Size int64
+}
+
+// PayloadOffset returns the offset of the payload of the box.
+func (h BoxHeader) PayloadOffset() int64 {
+	return h.Offset + h.HeaderSize
+}
+
+// PayloadSize returns the size of the payload of the box.
+func (h BoxHeader) PayloadSize() int64 {
+	return h.Size - h.HeaderSize
+}
+
+// ReadBoxHeader reads the header of the box at offset. end is the end
+// of the enclosing box or file.
+func ReadBoxHeader(r *io.SectionReader, offset int64, end int64) (BoxHeader, error) {
+	h := BoxHeader{
+		Offset:     offset,
+		HeaderSize: boxSize,
+	}
+
+	buf, err := fileformats.ReadAt(r, offset, boxSize)
+	if err != nil || offset+boxSize > end {
+		return h, returnErr(fileformats.ErrImageNotRecognized)
+	}
+
+	length, boxType := parseBox(buf)
+	h.Type = boxType
+	h.Size = int64(length)
+
+	switch length {
+	case 0:
+		// Box extends to end of file.
+		h.Size = end - offset
+
+	case 1:
+		// 64-bit length
+		buf, err = fileformats.ReadAt(r, offset+boxSize, 8)
+		if err != nil {
+			return h, returnErr(fileformats.ErrImageNotRecognized)
+		}
+
+		h.Size = int64(binary.BigEndian.Uint64(buf))
+		h.HeaderSize += 8
+	}
+
+	if boxType == "uuid" {
+		buf, err = fileformats.ReadAt(r, offset+h.HeaderSize, 16)
+		if err != nil {
+			return h, returnErr(fileformats.ErrImageNotRecognized)
+		}
+
+		h.UserType = string(buf)
+		h.HeaderSize += 16
+	}
+
+	debugf("ReadBoxHeader: type:%s length:%d", h.Type, h.Size)
+
+	if h.Size < h.HeaderSize || h.Size > end-offset {
+		return h, returnErr(fileformats.ErrImageNotRecognized)
+	}
+
+	return h, nil
+}
+
+// ReadBoxHeaders reads the headers of all boxes found between offset
+// and end. On error the headers read before the malformed box are
+// returned along with the error.
+func ReadBoxHeaders(r *io.SectionReader, offset int64, end int64) ([]BoxHeader, error) {
+	var headers []BoxHeader
+
+	for end-offset >= boxSize {
+		h, err := ReadBoxHeader(r, offset, end)
+		if err != nil {
+			return headers, err
+		}
+
+		headers = append(headers, h)
+
+		offset += h.Size
+	}
+
+	return headers, nil
+}
@@ -0,0 +1,67 @@
+package bmff
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/abrander/apexif/fileformats"
+)
+
+// Ftyp is a type representing the file type box.
+type Ftyp struct {
+	MajorBrand       string
+	MinorVersion     uint32
+	CompatibleBrands []string
+}
+
+// HasBrand returns true if brand is either the major brand or one of
+// the compatible brands.
+func (f Ftyp) HasBrand(brand string) bool {
+	if f.MajorBrand == brand {
+		return true
+	}
+
+	for _, b := range f.CompatibleBrands {
+		if b == brand {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReadFtyp reads the file type box. It must be the first box in r.
+func ReadFtyp(r *io.SectionReader) (Ftyp, error) {
+	h, err := ReadBoxHeader(r, 0, r.Size())
+	if err != nil {
+		return Ftyp{}, err
+	}
+
+	if h.Type != "ftyp" {
+		return Ftyp{}, returnErr(fileformats.ErrImageNotRecognized)
+	}
+
+	data, err := fileformats.ReadAt(r, h.PayloadOffset(), h.PayloadSize())
+	if err != nil {
+		return Ftyp{}, returnErr(fileformats.ErrImageNotRecognized)
+	}
+
+	return parseFtypPayload(data)
+}
+
+func parseFtypPayload(data []byte) (Ftyp, error) {
+	if len(data) < 8 {
+		return Ftyp{}, returnErr(fileformats.ErrImageNotRecognized)
+	}
+
+	f := Ftyp{
+		MajorBrand:   string(data[0:4]),
+		MinorVersion: binary.BigEndian.Uint32(data[4:8]),
+	}
+
+	for data = data[8:]; len(data) >= 4; data = data[4:] {
+		f.CompatibleBrands = append(f.CompatibleBrands, string(data[0:4]))
+	}
+
+	return f, nil
+}
@@ -0,0 +1,72 @@
+package bmff
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ImageGrid is a type representing a derived grid image. The tiles
+// are listed in row-major order.
+type ImageGrid struct {
+	Rows         int
+	Columns      int
+	OutputWidth  uint32
+	OutputHeight uint32
+	Tiles        []uint32
+}
+
+// ErrNotGrid is returned by Grid if the item is not a grid image.
+var ErrNotGrid = errors.New("item is not a grid image")
+
+// Grid returns the grid description of the grid item with the given
+// ID.
+func (b *Bmff) Grid(id uint32) (ImageGrid, error) {
+	var grid ImageGrid
+
+	item := b.findItem(id)
+	if item == nil {
+		return grid, ErrItemNotFound
+	}
+
+	if item.itemType != "grid" {
+		return grid, ErrNotGrid
+	}
+
+	data, err := b.itemData(item)
+	if err != nil {
+		return grid, err
+	}
+
+	if len(data) < 4 {
+		return grid, ErrNotGrid
+	}
+
+	flags := data[1]
+	grid.Rows = int(data[2]) + 1
+	grid.Columns = int(data[3]) + 1
+	data = data[4:]
+
+	if flags&1 == 1 {
+		if len(data) < 8 {
+			return grid, ErrNotGrid
+		}
+
+		grid.OutputWidth = binary.BigEndian.Uint32(data[0:])
+		grid.OutputHeight = binary.BigEndian.Uint32(data[4:])
+	} else {
+		if len(data) < 4 {
+			return grid, ErrNotGrid
+		}
+
+		grid.OutputWidth = uint32(binary.BigEndian.Uint16(data[0:]))
+		grid.OutputHeight = uint32(binary.BigEndian.Uint16(data[2:]))
+	}
+
+	for _, ref := range item.references {
+		if ref.Type == "dimg" {
+			grid.Tiles = append(grid.Tiles, ref.To...)
+		}
+	}
+
+	return grid, nil
+}
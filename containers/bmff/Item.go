@@ -0,0 +1,544 @@
+package bmff
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/abrander/apexif/fileformats"
+)
+
+// item is an item as described by the iinf and iloc boxes.
+type item struct {
+	id          uint32
+	itemType    string
+	name        string
+	contentType string
+
+	constructionMethod uint8
+	baseOffset         uint64
+	extents            []extent
+
+	// properties holds the 1-based indexes of the properties in ipco
+	// associated with the item.
+	properties []int
+
+	references []Reference
+}
+
+// Item is a type describing an item in the container.
+type Item struct {
+	ID          uint32
+	Type        string
+	Name        string
+	ContentType string
+
+	// Extents are the byte ranges in the file holding the item data.
+	// They are nil if the item data is not stored in the file as is.
+	Extents []Extent
+
+	// References are the references from this item to other items.
+	References []Reference
+}
+
+// Extent is a type representing a byte range in the file.
+type Extent struct {
+	Offset int64
+	Length int64
+}
+
+// Reference is a type representing a typed reference from an item to
+// one or more other items, as found in the iref box. Common types are
+// "thmb" (thumbnail), "auxl" (auxiliary image), "dimg" (derived image
+// like grid tiles) and "cdsc" (content description like Exif).
+type Reference struct {
+	Type string
+	To   []uint32
+}
+
+// extent is a single extent of an item.
+type extent struct {
+	index  uint64
+	offset uint64
+	length uint64
+}
+
+// Construction methods as defined for the iloc box.
+const (
+	constructionFile   = 0
+	constructionIdat   = 1
+	constructionOffset = 2
+)
+
+var errUnsupportedConstruction = errors.New("unsupported item construction method")
+
+// findItem returns the item with the given ID or nil if not found.
+func (b *Bmff) findItem(id uint32) *item {
+	return b.itemsByID[id]
+}
+
+// item returns the item with the given ID, creating it if needed. The
+// iinf and iloc boxes can come in any order.
+func (b *Bmff) item(id uint32) *item {
+	if i := b.findItem(id); i != nil {
+		return i
+	}
+
+	if b.itemsByID == nil {
+		b.itemsByID = make(map[uint32]*item)
+	}
+
+	i := &item{id: id}
+	b.items = append(b.items, i)
+	b.itemsByID[id] = i
+
+	return i
+}
+
+// readUint reads an unsigned big endian integer of size bytes from
+// the start of data. size can be 0, 2, 4 or 8. The rest of data is
+// returned.
+func readUint(data []byte, size int) (uint64, []byte, bool) {
+	if len(data) < size {
+		return 0, data, false
+	}
+
+	switch size {
+	case 0:
+		return 0, data, true
+
+	case 2:
+		return uint64(binary.BigEndian.Uint16(data)), data[2:], true
+
+	case 4:
+		return uint64(binary.BigEndian.Uint32(data)), data[4:], true
+
+	case 8:
+		return binary.BigEndian.Uint64(data), data[8:], true
+	}
+
+	return 0, data, false
+}
+
+// readString reads a null terminated string from the start of data.
+// The rest of data is returned.
+func readString(data []byte) (string, []byte) {
+	for i, c := range data {
+		if c == 0 {
+			return string(data[:i]), data[i+1:]
+		}
+	}
+
+	return string(data), nil
+}
+
+func (b *Bmff) parseIinf(iinf *Box) {
+	debugf("parseIinf version:%d items:%d", iinf.Version, len(iinf.Children))
+
+	for _, box := range iinf.Children {
+		if box.Type != "infe" {
+			continue
+		}
+
+		data, err := box.Payload()
+		if err != nil {
+			debugf("parseIinf: %s", err)
+
+			return
+		}
+
+		b.parseInfe(box.Version, data)
+	}
+}
+
+func (b *Bmff) parseInfe(version uint8, data []byte) {
+	idSize := 2
+	if version > 2 {
+		idSize = 4
+	}
+
+	id, data, ok := readUint(data, idSize)
+	if !ok || len(data) < 2 {
+		debugf("parseInfe: Too short")
+
+		return
+	}
+
+	// Skip item_protection_index.
+	data = data[2:]
+
+	item := b.item(uint32(id))
+
+	if version >= 2 {
+		if len(data) < 4 {
+			debugf("parseInfe: Too short")
+
+			return
+		}
+
+		item.itemType = string(data[0:4])
+		data = data[4:]
+	}
+
+	item.name, data = readString(data)
+
+	if version < 2 || item.itemType == "mime" {
+		item.contentType, _ = readString(data)
+	}
+
+	debugf("parseInfe version:%d item:%d type:%s name:%s content-type:%s", version, item.id, item.itemType, item.name, item.contentType)
+}
+
+func (b *Bmff) parseIloc(version uint8, data []byte) {
+	if len(data) < 2 || version > 2 {
+		debugf("parseIloc: Too short or unknown version:%d", version)
+
+		return
+	}
+
+	offsetSize := int(data[0] >> 4)
+	lengthSize := int(data[0] & 0xf)
+	baseOffsetSize := int(data[1] >> 4)
+	indexSize := 0
+
+	if version > 0 {
+		indexSize = int(data[1] & 0xf)
+	}
+
+	data = data[2:]
+
+	idSize := 2
+	if version == 2 {
+		idSize = 4
+	}
+
+	items, data, ok := readUint(data, idSize)
+	if !ok {
+		debugf("parseIloc: Too short")
+
+		return
+	}
+
+	debugf("parseIloc: version:%d items:%d offset:%d length:%d base:%d index:%d", version, items, offsetSize, lengthSize, baseOffsetSize, indexSize)
+
+	for i := uint64(0); i < items; i++ {
+		var id, method, extents uint64
+
+		id, data, ok = readUint(data, idSize)
+		if !ok {
+			debugf("parseIloc pos:%d: Too short", i)
+
+			return
+		}
+
+		item := b.item(uint32(id))
+
+		if version > 0 {
+			method, data, ok = readUint(data, 2)
+			if !ok {
+				return
+			}
+
+			item.constructionMethod = uint8(method & 0xf)
+		}
+
+		// Skip data_reference_index. We only support data in the
+		// same file.
+		_, data, ok = readUint(data, 2)
+		if !ok {
+			return
+		}
+
+		item.baseOffset, data, ok = readUint(data, baseOffsetSize)
+		if !ok {
+			return
+		}
+
+		extents, data, ok = readUint(data, 2)
+		if !ok {
+			return
+		}
+
+		item.extents = make([]extent, extents)
+
+		for e := range item.extents {
+			ext := &item.extents[e]
+
+			ext.index, data, ok = readUint(data, indexSize)
+			if !ok {
+				return
+			}
+
+			ext.offset, data, ok = readUint(data, offsetSize)
+			if !ok {
+				return
+			}
+
+			ext.length, data, ok = readUint(data, lengthSize)
+			if !ok {
+				return
+			}
+		}
+
+		debugf("parseIloc pos:%d item:%d: tag:%s method:%d base:0x%x extents:%v", i, item.id, item.itemType, item.constructionMethod, item.baseOffset, item.extents)
+	}
+}
+
+// extents resolves the extents of an item to byte ranges in the file.
+func (b *Bmff) extents(item *item) ([]Extent, error) {
+	var start, end int64
+
+	switch item.constructionMethod {
+	case constructionFile:
+		start, end = 0, b.r.Size()
+
+	case constructionIdat:
+		if b.idat == nil {
+			return nil, ErrItemNotFound
+		}
+
+		start, end = b.idat.PayloadOffset(), b.idat.Offset+b.idat.Size
+
+	default:
+		return nil, errUnsupportedConstruction
+	}
+
+	extents := make([]Extent, len(item.extents))
+
+	if end < start {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	// The fields are 64 bit and can't be trusted, so the sums are
+	// checked against the range before converting to int64.
+	size := uint64(end - start)
+
+	for i, ext := range item.extents {
+		if item.baseOffset > size || ext.offset > size-item.baseOffset {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		offset := start + int64(item.baseOffset+ext.offset)
+		length := int64(ext.length)
+
+		if ext.length == 0 {
+			// The extent runs to the end of the file or idat.
+			length = end - offset
+		}
+
+		if ext.length > size || length > end-offset {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		extents[i] = Extent{Offset: offset, Length: length}
+	}
+
+	return extents, nil
+}
+
+// itemData returns the data of an item by concatenating its extents.
+func (b *Bmff) itemData(item *item) ([]byte, error) {
+	extents, err := b.extents(item)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+
+	for _, ext := range extents {
+		buf, err := fileformats.ReadAt(b.r, ext.Offset, ext.Length)
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, buf...)
+	}
+
+	return data, nil
+}
+
+// Items returns all items in the container.
+func (b *Bmff) Items() []Item {
+	items := make([]Item, len(b.items))
+
+	for i, item := range b.items {
+		items[i] = b.export(item)
+	}
+
+	return items
+}
+
+// Item returns the item with the given ID.
+func (b *Bmff) Item(id uint32) (Item, error) {
+	item := b.findItem(id)
+	if item == nil {
+		return Item{}, ErrItemNotFound
+	}
+
+	return b.export(item), nil
+}
+
+func (b *Bmff) export(item *item) Item {
+	extents, _ := b.extents(item)
+
+	return Item{
+		ID:          item.id,
+		Type:        item.itemType,
+		Name:        item.name,
+		ContentType: item.contentType,
+		Extents:     extents,
+		References:  item.references,
+	}
+}
+
+// ItemData returns the data of the item with the given ID.
+func (b *Bmff) ItemData(id uint32) ([]byte, error) {
+	item := b.findItem(id)
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+
+	return b.itemData(item)
+}
+
+// ReferencingItems returns the IDs of the items referencing the item
+// with the given ID using the given reference type. As an example the
+// thumbnails of an image are the items referencing it with "thmb".
+func (b *Bmff) ReferencingItems(id uint32, referenceType string) []uint32 {
+	var ids []uint32
+
+	for _, item := range b.items {
+		for _, ref := range item.references {
+			if ref.Type != referenceType {
+				continue
+			}
+
+			for _, to := range ref.To {
+				if to == id {
+					ids = append(ids, item.id)
+				}
+			}
+		}
+	}
+
+	return ids
+}
+
+func (b *Bmff) parseIref(iref *Box) {
+	idSize := 2
+	if iref.Version > 0 {
+		idSize = 4
+	}
+
+	for _, box := range iref.Children {
+		data, err := box.Payload()
+		if err != nil {
+			continue
+		}
+
+		from, data, ok := readUint(data, idSize)
+		if !ok {
+			continue
+		}
+
+		count, data, ok := readUint(data, 2)
+		if !ok {
+			continue
+		}
+
+		ref := Reference{
+			Type: box.Type,
+			To:   make([]uint32, 0, count),
+		}
+
+		for i := uint64(0); i < count; i++ {
+			var to uint64
+
+			to, data, ok = readUint(data, idSize)
+			if !ok {
+				break
+			}
+
+			ref.To = append(ref.To, uint32(to))
+		}
+
+		item := b.item(uint32(from))
+		item.references = append(item.references, ref)
+
+		debugf("parseIref: item:%d type:%s to:%v", item.id, ref.Type, ref.To)
+	}
+}
+
+// metadataItem returns the first item matching match that describes
+// the primary item, or the first item matching if none describes it.
+func (b *Bmff) metadataItem(match func(*item) bool) *item {
+	for _, id := range b.ReferencingItems(b.primary, "cdsc") {
+		item := b.findItem(id)
+		if item != nil && match(item) {
+			return item
+		}
+	}
+
+	for _, item := range b.items {
+		if match(item) {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// Exif returns the TIFF structure of the Exif item describing the
+// primary item, or the first Exif item if none is linked to it. The
+// item starts with the offset to the TIFF header, which is usually
+// preceded by "Exif\0\0". If there is no Exif item, ErrItemNotFound
+// is returned.
+func (b *Bmff) Exif() ([]byte, error) {
+	item := b.metadataItem(func(i *item) bool {
+		return i.itemType == "Exif"
+	})
+
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+
+	data, err := b.itemData(item)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 4 {
+		return nil, ErrMalformedItem
+	}
+
+	offset := uint64(binary.BigEndian.Uint32(data)) + 4
+	if offset > uint64(len(data)) {
+		return nil, ErrMalformedItem
+	}
+
+	data = data[offset:]
+
+	// Some writers point to the "Exif\0\0" marker instead of the TIFF
+	// header.
+	if len(data) >= 6 && string(data[:6]) == "Exif\000\000" {
+		data = data[6:]
+	}
+
+	return data, nil
+}
+
+// XMP returns the XMP packet describing the primary item, or the first
+// XMP packet if none is linked to it. XMP is stored as a mime item
+// with the content type "application/rdf+xml". If there is no XMP
+// item, ErrItemNotFound is returned.
+func (b *Bmff) XMP() ([]byte, error) {
+	item := b.metadataItem(func(i *item) bool {
+		return i.itemType == "mime" && i.contentType == "application/rdf+xml"
+	})
+
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+
+	return b.itemData(item)
+}
@@ -0,0 +1,352 @@
+package bmff
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ImageSpatialExtents is a type representing the ispe property, the
+// width and height of an image as encoded.
+type ImageSpatialExtents struct {
+	Width  uint32
+	Height uint32
+}
+
+// ImageRotation is a type representing the irot property. Angle is
+// the rotation in degrees anti-clockwise, one of 0, 90, 180 or 270.
+type ImageRotation struct {
+	Angle int
+}
+
+// ImageMirror is a type representing the imir property. Axis is 0 for
+// mirroring about a vertical axis (left-right) and 1 for mirroring
+// about a horizontal axis (top-bottom).
+type ImageMirror struct {
+	Axis uint8
+}
+
+// PixelInformation is a type representing the pixi property.
+type PixelInformation struct {
+	BitsPerChannel []uint8
+}
+
+// ColourInformation is a type representing the colr property. For
+// the "nclx" type the coding-independent code points are set, for
+// "rICC" and "prof" ICC holds the profile.
+type ColourInformation struct {
+	Type string
+
+	ColourPrimaries         uint16
+	TransferCharacteristics uint16
+	MatrixCoefficients      uint16
+	FullRange               bool
+
+	ICC []byte
+}
+
+// CleanAperture is a type representing the clap property. All values
+// are fractions given as numerator and denominator.
+type CleanAperture struct {
+	WidthN, WidthD    uint32
+	HeightN, HeightD  uint32
+	HorizontalOffsetN int32
+	HorizontalOffsetD uint32
+	VerticalOffsetN   int32
+	VerticalOffsetD   uint32
+}
+
+// AuxiliaryType is a type representing the auxC property, identifying
+// the kind of an auxiliary image by URN, like
+// "urn:mpeg:hevc:2015:auxid:1" for alpha planes and
+// "urn:mpeg:hevc:2015:auxid:2" for depth maps.
+type AuxiliaryType struct {
+	URN     string
+	Subtype []byte
+}
+
+// ItemProperties is a type holding the known properties associated
+// with an item. Properties not associated with the item are nil.
+type ItemProperties struct {
+	Extents          *ImageSpatialExtents
+	Rotation         *ImageRotation
+	Mirror           *ImageMirror
+	PixelInformation *PixelInformation
+	Colour           []ColourInformation
+	CleanAperture    *CleanAperture
+	Auxiliary        *AuxiliaryType
+
+	// MirrorFirst is true if the mirroring is to be applied before the
+	// rotation. Transformative properties are applied in the order
+	// they are associated with the item in ipma.
+	MirrorFirst bool
+}
+
+var (
+	// ErrItemNotFound is returned if an item is not found.
+	ErrItemNotFound = errors.New("item not found")
+
+	// ErrNoPrimaryItem is returned if the container has no pitm box.
+	ErrNoPrimaryItem = errors.New("no primary item")
+
+	// ErrPropertyNotFound is returned if an item lacks a property.
+	ErrPropertyNotFound = errors.New("property not found")
+
+	// ErrMalformedItem is returned if the data of an item doesn't
+	// match its type.
+	ErrMalformedItem = errors.New("malformed item")
+)
+
+// PrimaryItem returns the ID of the primary item.
+func (b *Bmff) PrimaryItem() (uint32, error) {
+	if b.primary == 0 {
+		return 0, ErrNoPrimaryItem
+	}
+
+	return b.primary, nil
+}
+
+// ItemProperties returns the properties associated with the item.
+func (b *Bmff) ItemProperties(id uint32) (ItemProperties, error) {
+	var props ItemProperties
+
+	item := b.findItem(id)
+	if item == nil {
+		return props, ErrItemNotFound
+	}
+
+	for _, index := range item.properties {
+		if index < 1 || index > len(b.properties) {
+			continue
+		}
+
+		switch p := b.properties[index-1].(type) {
+		case ImageSpatialExtents:
+			props.Extents = &p
+
+		case ImageRotation:
+			props.Rotation = &p
+
+		case ImageMirror:
+			props.Mirror = &p
+			props.MirrorFirst = props.Rotation == nil
+
+		case PixelInformation:
+			props.PixelInformation = &p
+
+		case ColourInformation:
+			props.Colour = append(props.Colour, p)
+
+		case CleanAperture:
+			props.CleanAperture = &p
+
+		case AuxiliaryType:
+			props.Auxiliary = &p
+		}
+	}
+
+	return props, nil
+}
+
+func (b *Bmff) parsePitm(pitm *Box) {
+	data, err := pitm.Payload()
+	if err != nil {
+		return
+	}
+
+	idSize := 2
+	if pitm.Version > 0 {
+		idSize = 4
+	}
+
+	id, _, ok := readUint(data, idSize)
+	if ok {
+		b.primary = uint32(id)
+	}
+
+	debugf("parsePitm: primary:%d", b.primary)
+}
+
+func (b *Bmff) parseIprp(iprp *Box) {
+	for _, box := range iprp.Children {
+		switch box.Type {
+		case "ipco":
+			for _, prop := range box.Children {
+				b.properties = append(b.properties, parseProperty(prop))
+			}
+
+		case "ipma":
+			data, err := box.Payload()
+			if err == nil {
+				b.parseIpma(box.Version, box.Flags, data)
+			}
+		}
+	}
+}
+
+// parseProperty parses a property box. Unknown or broken properties
+// are returned as nil to keep the indexes intact.
+func parseProperty(box *Box) any {
+	data, err := box.Payload()
+	if err != nil {
+		return nil
+	}
+
+	switch box.Type {
+	case "ispe":
+		if len(data) < 8 {
+			return nil
+		}
+
+		return ImageSpatialExtents{
+			Width:  binary.BigEndian.Uint32(data[0:]),
+			Height: binary.BigEndian.Uint32(data[4:]),
+		}
+
+	case "irot":
+		if len(data) < 1 {
+			return nil
+		}
+
+		return ImageRotation{Angle: int(data[0]&0x3) * 90}
+
+	case "imir":
+		if len(data) < 1 {
+			return nil
+		}
+
+		return ImageMirror{Axis: data[0] & 0x1}
+
+	case "pixi":
+		if len(data) < 1 || len(data) < 1+int(data[0]) {
+			return nil
+		}
+
+		return PixelInformation{BitsPerChannel: data[1 : 1+int(data[0])]}
+
+	case "colr":
+		if len(data) < 4 {
+			return nil
+		}
+
+		c := ColourInformation{Type: string(data[0:4])}
+
+		switch c.Type {
+		case "nclx":
+			if len(data) < 11 {
+				return nil
+			}
+
+			c.ColourPrimaries = binary.BigEndian.Uint16(data[4:])
+			c.TransferCharacteristics = binary.BigEndian.Uint16(data[6:])
+			c.MatrixCoefficients = binary.BigEndian.Uint16(data[8:])
+			c.FullRange = data[10]&0x80 != 0
+
+		case "rICC", "prof":
+			c.ICC = data[4:]
+		}
+
+		return c
+
+	case "clap":
+		if len(data) < 32 {
+			return nil
+		}
+
+		return CleanAperture{
+			WidthN:            binary.BigEndian.Uint32(data[0:]),
+			WidthD:            binary.BigEndian.Uint32(data[4:]),
+			HeightN:           binary.BigEndian.Uint32(data[8:]),
+			HeightD:           binary.BigEndian.Uint32(data[12:]),
+			HorizontalOffsetN: int32(binary.BigEndian.Uint32(data[16:])),
+			HorizontalOffsetD: binary.BigEndian.Uint32(data[20:]),
+			VerticalOffsetN:   int32(binary.BigEndian.Uint32(data[24:])),
+			VerticalOffsetD:   binary.BigEndian.Uint32(data[28:]),
+		}
+
+	case "auxC":
+		a := AuxiliaryType{}
+		a.URN, a.Subtype = readString(data)
+
+		return a
+	}
+
+	return nil
+}
+
+func (b *Bmff) parseIpma(version uint8, flags uint32, data []byte) {
+	entries, data, ok := readUint(data, 4)
+	if !ok {
+		return
+	}
+
+	idSize := 2
+	if version > 0 {
+		idSize = 4
+	}
+
+	for i := uint64(0); i < entries; i++ {
+		var id uint64
+
+		id, data, ok = readUint(data, idSize)
+		if !ok || len(data) < 1 {
+			debugf("parseIpma pos:%d: Too short", i)
+
+			return
+		}
+
+		count := int(data[0])
+		data = data[1:]
+
+		item := b.item(uint32(id))
+
+		for a := 0; a < count; a++ {
+			var index int
+
+			// The top bit is the essential flag.
+			if flags&1 == 1 {
+				if len(data) < 2 {
+					return
+				}
+
+				index = int(binary.BigEndian.Uint16(data) & 0x7fff)
+				data = data[2:]
+			} else {
+				if len(data) < 1 {
+					return
+				}
+
+				index = int(data[0] & 0x7f)
+				data = data[1:]
+			}
+
+			if index > 0 {
+				item.properties = append(item.properties, index)
+			}
+		}
+
+		debugf("parseIpma pos:%d item:%d properties:%v", i, item.id, item.properties)
+	}
+}
+
+// ICCProfile returns the ICC profile of the primary item from its colr
+// property. If the primary item has no ICC profile,
+// ErrPropertyNotFound is returned.
+func (b *Bmff) ICCProfile() ([]byte, error) {
+	primary, err := b.PrimaryItem()
+	if err != nil {
+		return nil, err
+	}
+
+	props, err := b.ItemProperties(primary)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range props.Colour {
+		if len(c.ICC) > 0 {
+			return c.ICC, nil
+		}
+	}
+
+	return nil, ErrPropertyNotFound
+}
@@ -0,0 +1,338 @@
+package bplist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+	"unicode/utf16"
+)
+
+var (
+	// ErrNotBinaryPlist is returned if the data doesn't start with the
+	// binary property list header.
+	ErrNotBinaryPlist = errors.New("not a binary property list")
+
+	// ErrMalformed is returned if the trailer, offset table or an
+	// object is invalid.
+	ErrMalformed = errors.New("malformed binary property list")
+)
+
+const (
+	header      = "bplist00"
+	trailerSize = 32
+
+	// maxDepth limits the nesting of arrays and dictionaries.
+	maxDepth = 32
+)
+
+// UID is a reference to an object, as used by NSKeyedArchiver.
+type UID uint64
+
+// epoch is the reference date of dates in property lists.
+var epoch = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// plist is a binary property list being decoded.
+type plist struct {
+	data    []byte
+	offsets []uint64
+	refSize int
+
+	// decoded holds the objects decoded so far, and active the
+	// objects being decoded, to guard against reference loops.
+	decoded map[uint64]any
+	active  map[uint64]bool
+}
+
+// Parse decodes a binary property list. Values are returned as nil,
+// bool, int64, float64, time.Time, []byte, string, UID, []any and
+// map[string]any. Sets are returned as []any.
+func Parse(data []byte) (any, error) {
+	if !bytes.HasPrefix(data, []byte(header)) {
+		return nil, ErrNotBinaryPlist
+	}
+
+	if len(data) < len(header)+trailerSize {
+		return nil, ErrMalformed
+	}
+
+	trailer := data[len(data)-trailerSize:]
+	offsetSize := int(trailer[6])
+	refSize := int(trailer[7])
+	count := binary.BigEndian.Uint64(trailer[8:])
+	top := binary.BigEndian.Uint64(trailer[16:])
+	tableOffset := binary.BigEndian.Uint64(trailer[24:])
+
+	if offsetSize < 1 || offsetSize > 8 || refSize < 1 || refSize > 8 || top >= count {
+		return nil, ErrMalformed
+	}
+
+	end := uint64(len(data) - trailerSize)
+	if tableOffset >= end || count > (end-tableOffset)/uint64(offsetSize) {
+		return nil, ErrMalformed
+	}
+
+	p := &plist{
+		data:    data[:end],
+		offsets: make([]uint64, count),
+		refSize: refSize,
+		decoded: make(map[uint64]any),
+		active:  make(map[uint64]bool),
+	}
+
+	for i := range p.offsets {
+		start := tableOffset + uint64(i*offsetSize)
+		p.offsets[i] = bigEndian(data[start : start+uint64(offsetSize)])
+	}
+
+	return p.object(top, 0)
+}
+
+// bigEndian decodes a big endian unsigned integer of up to 8 bytes.
+func bigEndian(b []byte) uint64 {
+	var v uint64
+	for _, x := range b {
+		v = v<<8 | uint64(x)
+	}
+
+	return v
+}
+
+// read returns length bytes at offset.
+func (p *plist) read(offset uint64, length uint64) ([]byte, error) {
+	if offset > uint64(len(p.data)) || length > uint64(len(p.data))-offset {
+		return nil, ErrMalformed
+	}
+
+	return p.data[offset : offset+length], nil
+}
+
+// object decodes the object with the given reference. Objects
+// referenced more than once are only decoded once.
+func (p *plist) object(ref uint64, depth int) (any, error) {
+	if ref >= uint64(len(p.offsets)) || depth > maxDepth || p.active[ref] {
+		return nil, ErrMalformed
+	}
+
+	if v, ok := p.decoded[ref]; ok {
+		return v, nil
+	}
+
+	p.active[ref] = true
+	defer delete(p.active, ref)
+
+	v, err := p.decode(ref, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	p.decoded[ref] = v
+
+	return v, nil
+}
+
+// decode decodes the object with the given reference.
+func (p *plist) decode(ref uint64, depth int) (any, error) {
+	offset := p.offsets[ref]
+
+	marker, err := p.read(offset, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := marker[0] >> 4
+	info := uint64(marker[0] & 0x0f)
+	offset++
+
+	switch kind {
+	case 0x0:
+		switch info {
+		case 0x0:
+			return nil, nil
+		case 0x8:
+			return false, nil
+		case 0x9:
+			return true, nil
+		}
+
+	case 0x1:
+		// Integers of 8 bytes and less are signed, 16 byte integers
+		// are unsigned and only the low 8 bytes are kept.
+		if info > 4 {
+			break
+		}
+
+		b, err := p.read(offset, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(b) == 16 {
+			b = b[8:]
+		}
+
+		return int64(bigEndian(b)), nil
+
+	case 0x2:
+		b, err := p.read(offset, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+
+		switch len(b) {
+		case 4:
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+		case 8:
+			return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+		}
+
+	case 0x3:
+		b, err := p.read(offset, 8)
+		if err != nil {
+			return nil, err
+		}
+
+		seconds := math.Float64frombits(binary.BigEndian.Uint64(b))
+
+		return epoch.Add(time.Duration(seconds * float64(time.Second))), nil
+
+	case 0x4, 0x5, 0x6:
+		length, offset, err := p.length(info, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		if kind == 0x6 {
+			length *= 2
+		}
+
+		b, err := p.read(offset, length)
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case 0x4:
+			return append([]byte{}, b...), nil
+		case 0x5:
+			return string(b), nil
+		default:
+			u := make([]uint16, len(b)/2)
+			for i := range u {
+				u[i] = binary.BigEndian.Uint16(b[2*i:])
+			}
+
+			return string(utf16.Decode(u)), nil
+		}
+
+	case 0x8:
+		b, err := p.read(offset, info+1)
+		if err != nil {
+			return nil, err
+		}
+
+		return UID(bigEndian(b)), nil
+
+	case 0xa, 0xc:
+		refs, err := p.refs(info, offset, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]any, len(refs))
+		for i, ref := range refs {
+			values[i], err = p.object(ref, depth+1)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return values, nil
+
+	case 0xd:
+		refs, err := p.refs(info, offset, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		n := len(refs) / 2
+		values := make(map[string]any, n)
+
+		for i := 0; i < n; i++ {
+			key, err := p.object(refs[i], depth+1)
+			if err != nil {
+				return nil, err
+			}
+
+			k, ok := key.(string)
+			if !ok {
+				return nil, ErrMalformed
+			}
+
+			values[k], err = p.object(refs[n+i], depth+1)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return values, nil
+	}
+
+	return nil, ErrMalformed
+}
+
+// length returns the length of a data, string, array or dictionary
+// object, and the offset of its contents. Lengths of 15 and above are
+// stored as an integer object following the marker.
+func (p *plist) length(info uint64, offset uint64) (uint64, uint64, error) {
+	if info != 0x0f {
+		return info, offset, nil
+	}
+
+	marker, err := p.read(offset, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if marker[0]>>4 != 0x1 || marker[0]&0x0f > 3 {
+		return 0, 0, ErrMalformed
+	}
+
+	size := uint64(1) << (marker[0] & 0x0f)
+
+	b, err := p.read(offset+1, size)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return bigEndian(b), offset + 1 + size, nil
+}
+
+// refs returns the object references of an array or dictionary. A
+// dictionary holds two references per entry, the keys followed by the
+// values.
+func (p *plist) refs(info uint64, offset uint64, per uint64) ([]uint64, error) {
+	length, offset, err := p.length(info, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if length > uint64(len(p.data))/per {
+		return nil, ErrMalformed
+	}
+
+	size := uint64(p.refSize)
+
+	b, err := p.read(offset, length*per*size)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]uint64, length*per)
+	for i := range refs {
+		refs[i] = bigEndian(b[uint64(i)*size : uint64(i+1)*size])
+	}
+
+	return refs, nil
+}
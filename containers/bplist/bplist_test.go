@@ -0,0 +1,115 @@
+package bplist
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fixture is a dictionary with one value of each type, written by
+// Python's plistlib with sorted keys.
+const fixture = "" +
+	"\x62\x70\x6c\x69\x73\x74\x30\x30\xdd\x01\x02\x03\x04\x05\x06\x07" +
+	"\x08\x09\x0a\x0b\x0c\x0d\x0e\x13\x14\x15\x16\x19\x1a\x1b\x1c\x1d" +
+	"\x1e\x1f\x20\x55\x61\x72\x72\x61\x79\x53\x62\x69\x67\x54\x64\x61" +
+	"\x74\x61\x54\x64\x61\x74\x65\x54\x64\x69\x63\x74\x55\x66\x61\x6c" +
+	"\x73\x65\x55\x66\x6c\x6f\x61\x74\x53\x69\x6e\x74\x53\x6e\x65\x67" +
+	"\x53\x73\x74\x72\x54\x74\x72\x75\x65\x53\x75\x69\x64\x53\x75\x6e" +
+	"\x69\xa3\x0f\x10\x11\x10\x01\x53\x74\x77\x6f\xa1\x12\x10\x03\x13" +
+	"\x00\x00\x01\x00\x00\x00\x00\x00\x43\x00\x01\x02\x33\x41\xc2\xf8" +
+	"\x51\x1f\x80\x00\x00\xd1\x17\x18\x51\x61\x51\x62\x08\x23\x3f\xf8" +
+	"\x00\x00\x00\x00\x00\x00\x10\x2a\x13\xff\xff\xff\xff\xff\xff\xff" +
+	"\xf9\x55\x68\x65\x6c\x6c\x6f\x09\x80\x05\x66\x00\x62\x00\x6c\x00" +
+	"\xe5\x00\x62\x00\xe6\x00\x72\x08\x23\x29\x2d\x32\x37\x3c\x42\x48" +
+	"\x4c\x50\x54\x59\x5d\x61\x65\x67\x6b\x6d\x6f\x78\x7c\x85\x88\x8a" +
+	"\x8c\x8d\x96\x98\xa1\xa7\xa8\xaa\x00\x00\x00\x00\x00\x00\x01\x01" +
+	"\x00\x00\x00\x00\x00\x00\x00\x21\x00\x00\x00\x00\x00\x00\x00\x00" +
+	"\x00\x00\x00\x00\x00\x00\x00\xb7"
+
+// trailer returns a trailer with 1 byte offsets and references.
+func trailer(count byte, top byte, table byte) string {
+	return "\x00\x00\x00\x00\x00\x00\x01\x01" +
+		"\x00\x00\x00\x00\x00\x00\x00" + string(count) +
+		"\x00\x00\x00\x00\x00\x00\x00" + string(top) +
+		"\x00\x00\x00\x00\x00\x00\x00" + string(table)
+}
+
+func TestParse(t *testing.T) {
+	v, err := Parse([]byte(fixture))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := map[string]any{
+		"array": []any{int64(1), "two", []any{int64(3)}},
+		"big":   int64(1 << 40),
+		"data":  []byte{0, 1, 2},
+		"date":  time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC),
+		"dict":  map[string]any{"a": "b"},
+		"false": false,
+		"float": 1.5,
+		"int":   int64(42),
+		"neg":   int64(-7),
+		"str":   "hello",
+		"true":  true,
+		"uid":   UID(5),
+		"uni":   "blåbær",
+	}
+
+	got, ok := v.(map[string]any)
+	if !ok {
+		t.Fatalf("got %T, expected a dictionary", v)
+	}
+
+	for key, w := range want {
+		if !reflect.DeepEqual(got[key], w) {
+			t.Errorf("%s: got %#v, expected %#v", key, got[key], w)
+		}
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("got %d keys, expected %d", len(got), len(want))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"not a plist", "<?xml version=\"1.0\"?><plist/>", ErrNotBinaryPlist},
+		{"truncated", fixture[:100], ErrMalformed},
+		{"no trailer", "bplist00\x08", ErrMalformed},
+
+		// An array containing itself.
+		{"loop", "bplist00\xa1\x00\x08" + trailer(1, 0, 10), ErrMalformed},
+
+		// A reference to an object not in the offset table.
+		{"bad reference", "bplist00\xa1\x01\x08" + trailer(1, 0, 10), ErrMalformed},
+
+		// A string running past the end of the objects.
+		{"bad length", "bplist00\x5f\x10\x7f\x08" + trailer(1, 0, 11), ErrMalformed},
+	}
+
+	for _, c := range cases {
+		_, err := Parse([]byte(c.data))
+		if err != c.want {
+			t.Errorf("%s: got %v, expected %v", c.name, err, c.want)
+		}
+	}
+}
+
+func TestParseShared(t *testing.T) {
+	// An array referencing the same string twice.
+	data := "bplist00\xa2\x01\x01\x51\x61\x08\x0b" + trailer(2, 0, 13)
+
+	v, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(v, []any{"a", "a"}) {
+		t.Errorf("got %#v, expected [a a]", v)
+	}
+}
@@ -0,0 +1,153 @@
+package exif
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/abrander/apexif/containers/tiff"
+)
+
+// TimeSource tells how the timezone of a capture time was determined.
+type TimeSource uint8
+
+const (
+	// TimeSourceOffset means the offset was read from one of the
+	// OffsetTime tags.
+	TimeSourceOffset TimeSource = iota + 1
+
+	// TimeSourceGPS means the offset was derived from the difference
+	// between the GPS UTC time and the local camera time.
+	TimeSourceGPS
+
+	// TimeSourceFallback means no offset was found, and the location
+	// passed by the caller was used. The time is uncertain.
+	TimeSourceFallback
+)
+
+var _ fmt.Stringer = TimeSource(0)
+
+// String returns a string representation of the source.
+func (s TimeSource) String() string {
+	switch s {
+	case TimeSourceOffset:
+		return "Offset"
+	case TimeSourceGPS:
+		return "GPS"
+	case TimeSourceFallback:
+		return "Fallback"
+	default:
+		return "Unknown"
+	}
+}
+
+// dateTimeTag is a datetime tag along with the tags qualifying it.
+type dateTimeTag struct {
+	dateTime Tag
+	offset   Tag
+	subSec   Tag
+}
+
+var (
+	originalTags  = dateTimeTag{DateTimeOriginal, OffsetTimeOriginal, SubSecTimeOriginal}
+	digitizedTags = dateTimeTag{DateTimeDigitized, OffsetTimeDigitized, SubSecTimeDigitized}
+	modifiedTags  = dateTimeTag{Tag(tiff.Datetime), OffsetTime, SubSecTime}
+)
+
+// dateTimeTags are the datetime tags in order of preference for the
+// capture time.
+var dateTimeTags = []dateTimeTag{originalTags, digitizedTags, modifiedTags}
+
+// CaptureTime returns the time the image was captured as an absolute
+// instant, and how the timezone was determined. DateTimeOriginal is
+// used if present, otherwise DateTimeDigitized or DateTime.
+//
+// The offset is taken from the OffsetTime tag belonging to the
+// datetime tag, then from any other OffsetTime tag. If none is present,
+// the offset is derived from the GPS time. If that fails too, fallback
+// is used. A nil fallback means UTC.
+func (e *Exif) CaptureTime(fallback *time.Location) (time.Time, TimeSource, error) {
+	var (
+		local time.Time
+		tags  dateTimeTag
+		err   error
+	)
+
+	for _, tags = range dateTimeTags {
+		local, err = e.dateTime(tags, time.UTC)
+		if err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	if loc, err := e.offsetLocation(tags.offset); err == nil {
+		return inLocation(local, loc), TimeSourceOffset, nil
+	}
+
+	for _, other := range dateTimeTags {
+		if loc, err := e.offsetLocation(other.offset); err == nil {
+			return inLocation(local, loc), TimeSourceOffset, nil
+		}
+	}
+
+	if loc, err := e.gpsLocation(local); err == nil {
+		return inLocation(local, loc), TimeSourceGPS, nil
+	}
+
+	if fallback == nil {
+		fallback = time.UTC
+	}
+
+	return inLocation(local, fallback), TimeSourceFallback, nil
+}
+
+// inLocation returns the wall clock time of local, interpreted in loc.
+func inLocation(local time.Time, loc *time.Location) time.Time {
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), loc)
+}
+
+// offsetLocation returns a fixed zone for an OffsetTime tag like
+// "+02:00".
+func (e *Exif) offsetLocation(tag Tag) (*time.Location, error) {
+	str, err := e.Ascii(AnyIFD, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := time.Parse("-07:00", str)
+	if err != nil {
+		return nil, err
+	}
+
+	_, offset := t.Zone()
+
+	return time.FixedZone(str, offset), nil
+}
+
+// gpsLocation derives a fixed zone from the difference between local,
+// the camera time, and the GPS UTC time. Timezone offsets are whole
+// quarter hours, so the difference is rounded to that. Differences
+// outside the range of real timezones are rejected.
+func (e *Exif) gpsLocation(local time.Time) (*time.Location, error) {
+	gps, err := e.GPSInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	utc, err := gps.Time()
+	if err != nil {
+		return nil, err
+	}
+
+	diff := local.Sub(utc)
+	offset := diff.Round(15 * time.Minute)
+
+	if offset > 14*time.Hour || offset < -12*time.Hour {
+		return nil, fmt.Errorf("GPS time too far from camera time: %s", diff)
+	}
+
+	return time.FixedZone("", int(offset/time.Second)), nil
+}
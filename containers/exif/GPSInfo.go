@@ -1,6 +1,9 @@
 package exif
 
 import (
+	"errors"
+	"time"
+
 	"github.com/abrander/apexif/containers/tiff"
 )
 
@@ -14,17 +17,12 @@ type GPSInfo struct {
 
 // GPSInfo returns the GPSInfo IFD or an error.
 func (e *Exif) GPSInfo() (*GPSInfo, error) {
-	entry, err := e.Tiff.Entry(AnyIFD, tiff.GPSInfoIFDPointer)
+	node, err := e.Node(GPSPath)
 	if err != nil {
 		return nil, err
 	}
 
-	ifd, err := e.Tiff.ReadIFD(entry.Offset())
-	if err != nil {
-		return nil, err
-	}
-
-	return &GPSInfo{ifd, e}, nil
+	return &GPSInfo{node.Entries, e}, nil
 }
 
 // Date returns the GPS date as a string or an error.
@@ -41,3 +39,223 @@ func (i *GPSInfo) Date() (string, error) {
 
 	return str, nil
 }
+
+// SpeedRef is the unit of GPSSpeed.
+type SpeedRef string
+
+const (
+	KilometersPerHour SpeedRef = "K"
+	MilesPerHour      SpeedRef = "M"
+	Knots             SpeedRef = "N"
+)
+
+// KilometersPerHour returns speed, given in unit r, in kilometers per
+// hour.
+func (r SpeedRef) KilometersPerHour(speed float64) float64 {
+	switch r {
+	case MilesPerHour:
+		return speed * 1.609344
+
+	case Knots:
+		return speed * 1.852
+
+	default:
+		return speed
+	}
+}
+
+// DirectionRef is the reference for directions like GPSTrack and
+// GPSImgDirection.
+type DirectionRef string
+
+const (
+	TrueNorth     DirectionRef = "T"
+	MagneticNorth DirectionRef = "M"
+)
+
+// rational returns the value of a single rational tag.
+func (i *GPSInfo) rational(tag Tag) (float64, error) {
+	entry, err := i.Entry(tiff.Tag(tag))
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := entry.Rational()
+	if err != nil {
+		return 0, err
+	}
+
+	return rationalFloat(r), nil
+}
+
+// rationalFloat returns the value of r. Some writers use 0/0 for
+// zero, so a zero denominator gives zero rather than NaN.
+func rationalFloat(r tiff.UnsignedRational) float64 {
+	if r.Denominator == 0 {
+		return 0
+	}
+
+	return r.Float()
+}
+
+// ref returns the value of a reference tag, or def if not present.
+func (i *GPSInfo) ref(tag Tag, def string) string {
+	entry, err := i.Entry(tiff.Tag(tag))
+	if err != nil {
+		return def
+	}
+
+	str, err := entry.Ascii()
+	if err != nil || str == "" {
+		return def
+	}
+
+	return str
+}
+
+// coordinate returns a coordinate in decimal degrees from a degrees,
+// minutes and seconds tag. Coordinates are negated if the reference
+// tag holds negative.
+func (i *GPSInfo) coordinate(tag Tag, refTag Tag, negative string) (float64, error) {
+	entry, err := i.Entry(tiff.Tag(tag))
+	if err != nil {
+		return 0, err
+	}
+
+	dms, err := entry.RationalSlice()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(dms) != 3 {
+		return 0, errors.New("coordinate is not degrees, minutes and seconds")
+	}
+
+	c := rationalFloat(dms[0]) + rationalFloat(dms[1])/60 + rationalFloat(dms[2])/3600
+
+	if i.ref(refTag, "") == negative {
+		c = -c
+	}
+
+	return c, nil
+}
+
+// Latitude returns the latitude in decimal degrees, negative for the
+// southern hemisphere.
+func (i *GPSInfo) Latitude() (float64, error) {
+	return i.coordinate(GPSLatitude, GPSLatitudeRef, "S")
+}
+
+// Longitude returns the longitude in decimal degrees, negative for the
+// western hemisphere.
+func (i *GPSInfo) Longitude() (float64, error) {
+	return i.coordinate(GPSLongitude, GPSLongitudeRef, "W")
+}
+
+// LatLon returns both latitude and longitude in decimal degrees.
+func (i *GPSInfo) LatLon() (float64, float64, error) {
+	lat, err := i.Latitude()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	lon, err := i.Longitude()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return lat, lon, nil
+}
+
+// Altitude returns the altitude in meters, negative below sea level.
+func (i *GPSInfo) Altitude() (float64, error) {
+	altitude, err := i.rational(GPSAltitude)
+	if err != nil {
+		return 0, err
+	}
+
+	entry, err := i.Entry(tiff.Tag(GPSAltitudeRef))
+	if err == nil {
+		ref, err := entry.Byte()
+		if err == nil && ref == 1 {
+			altitude = -altitude
+		}
+	}
+
+	return altitude, nil
+}
+
+// Time returns the UTC time of the GPS fix from GPSDateStamp and
+// GPSTimeStamp.
+func (i *GPSInfo) Time() (time.Time, error) {
+	date, err := i.Date()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	day, err := time.ParseInLocation("2006:01:02", date, time.UTC)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	entry, err := i.Entry(tiff.Tag(GPSTimeStamp))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	hms, err := entry.RationalSlice()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if len(hms) != 3 {
+		return time.Time{}, errors.New("time stamp is not hours, minutes and seconds")
+	}
+
+	seconds := rationalFloat(hms[0])*3600 + rationalFloat(hms[1])*60 + rationalFloat(hms[2])
+
+	return day.Add(time.Duration(seconds * float64(time.Second))), nil
+}
+
+// Speed returns the speed of the GPS receiver and its unit.
+func (i *GPSInfo) Speed() (float64, SpeedRef, error) {
+	speed, err := i.rational(GPSSpeed)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return speed, SpeedRef(i.ref(GPSSpeedRef, string(KilometersPerHour))), nil
+}
+
+// Track returns the direction of movement in degrees and its
+// reference.
+func (i *GPSInfo) Track() (float64, DirectionRef, error) {
+	track, err := i.rational(GPSTrack)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return track, DirectionRef(i.ref(GPSTrackRef, string(TrueNorth))), nil
+}
+
+// ImgDirection returns the direction the camera was pointing in
+// degrees and its reference.
+func (i *GPSInfo) ImgDirection() (float64, DirectionRef, error) {
+	direction, err := i.rational(GPSImgDirection)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return direction, DirectionRef(i.ref(GPSImgDirectionRef, string(TrueNorth))), nil
+}
+
+// DOP returns the dilution of precision.
+func (i *GPSInfo) DOP() (float64, error) {
+	return i.rational(GPSDOP)
+}
+
+// HPositioningError returns the horizontal positioning error in
+// meters.
+func (i *GPSInfo) HPositioningError() (float64, error) {
+	return i.rational(GPSHPositioningError)
+}
@@ -140,6 +140,15 @@ const (
 	GPSDifferential      Tag = 0x001e
 	GPSHPositioningError Tag = 0x001f
 
+	// Tags relating to interoperability. InteroperabilityIndex and
+	// InteroperabilityVersion share numbers with GPS tags, and are
+	// named as such by String.
+	InteroperabilityIndex   Tag = 0x0001
+	InteroperabilityVersion Tag = 0x0002
+	RelatedImageFileFormat  Tag = 0x1000
+	RelatedImageWidth       Tag = 0x1001
+	RelatedImageLength      Tag = 0x1002
+
 	InteroperabilityIFDPointer Tag = 0xa005
 )
 
@@ -268,6 +277,10 @@ func (t Tag) String() string {
 		GPSDifferential:      "GPSDifferential",
 		GPSHPositioningError: "GPSHPositioningError",
 
+		RelatedImageFileFormat: "RelatedImageFileFormat",
+		RelatedImageWidth:      "RelatedImageWidth",
+		RelatedImageLength:     "RelatedImageLength",
+
 		InteroperabilityIFDPointer: "InteroperabilityIFDPointer",
 	}
 
@@ -0,0 +1,99 @@
+package exif
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/abrander/apexif/containers/tiff"
+)
+
+var (
+	// ErrNoThumbnail is returned if no thumbnail is found.
+	ErrNoThumbnail = errors.New("no thumbnail found")
+
+	// ErrNoPreview is returned if no preview image is found.
+	ErrNoPreview = errors.New("no preview found")
+)
+
+// Thumbnail returns the thumbnail stored in IFD1. This is usually a
+// small JPEG, but can be uncompressed strips as well.
+func (e *Exif) Thumbnail() ([]byte, error) {
+	ifds := e.Tiff.IFDs()
+	if len(ifds) < 2 {
+		return nil, ErrNoThumbnail
+	}
+
+	data, err := ifds[1].ImageData()
+	if err == tiff.ErrNoImageData {
+		return nil, ErrNoThumbnail
+	}
+
+	return data, err
+}
+
+// Compression values for JPEG.
+const (
+	compressionOldJPEG = 6
+	compressionJPEG    = 7
+)
+
+// Preview returns the largest JPEG preview image found in the SubIFDs
+// of IFD0, as used by DNG and NEF files.
+func (e *Exif) Preview() ([]byte, error) {
+	root, err := e.Node("IFD0")
+	if err != nil {
+		return nil, ErrNoPreview
+	}
+
+	var preview []byte
+
+	for _, node := range root.Children {
+		if !strings.HasPrefix(node.Name, "SubIFD") {
+			continue
+		}
+
+		ifd := node.Entries
+
+		// Only reduced resolution images are previews, the full
+		// resolution image is the raw data.
+		subfileType, err := ifd.Entry(tiff.NewSubfileType)
+		if err != nil {
+			continue
+		}
+
+		if t, err := subfileType.Int(); err != nil || t&1 == 0 {
+			continue
+		}
+
+		if !isJPEG(ifd) {
+			continue
+		}
+
+		data, err := ifd.ImageData()
+		if err == nil && len(data) > len(preview) {
+			preview = data
+		}
+	}
+
+	if preview == nil {
+		return nil, ErrNoPreview
+	}
+
+	return preview, nil
+}
+
+// isJPEG returns true if the IFD references JPEG image data.
+func isJPEG(ifd tiff.IFD) bool {
+	if _, err := ifd.Entry(tiff.JPEGInterchangeFormat); err == nil {
+		return true
+	}
+
+	compression, err := ifd.Entry(tiff.Compression)
+	if err != nil {
+		return false
+	}
+
+	c, err := compression.Int()
+
+	return err == nil && (c == compressionOldJPEG || c == compressionJPEG)
+}
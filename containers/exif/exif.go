@@ -2,31 +2,48 @@ package exif
 
 import (
 	"errors"
+	"io"
 	"math"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/abrander/apexif/containers/icc"
+	"github.com/abrander/apexif/containers/iptc"
+	"github.com/abrander/apexif/containers/psirb"
 	"github.com/abrander/apexif/containers/tiff"
+	"github.com/abrander/apexif/containers/xmp"
 )
 
 // Exif is a type representing EXIF data from some source.
 type Exif struct {
 	tiff.Tiff
 
-	exifIDFPointer *tiff.IFD
+	makerNote *tiff.Tiff
 }
 
 var (
 	// ErrNoExifFound is returned if no EXIF data is found.
 	ErrNoExifFound = errors.New("no EXIF data found")
+
+	// ErrTagNotFound is returned if a tag is not found. It's the same
+	// error as tiff.ErrTagNotFound.
+	ErrTagNotFound = tiff.ErrTagNotFound
 )
 
 // AnyIFD is a constant used to indicate that any IFD can be searched.
 const AnyIFD = tiff.AnyIFD
 
-// unread is a pointer used to indicate that the IFD has not been read yet.
-var unread = &tiff.IFD{}
+// Paths of the IFDs holding EXIF tags in the IFD tree.
+const (
+	ExifPath    = "IFD0/Exif"
+	GPSPath     = "IFD0/GPS"
+	InteropPath = "IFD0/Exif/Interop"
+
+	// MakerNotePath is only present after the MakerNote is parsed by
+	// the makernote package, or for CR3 files storing it separately.
+	MakerNotePath = "IFD0/Exif/MakerNote"
+)
 
 // Parse parses the given data as EXIF data or returns an error.
 func Parse(data []byte) (*Exif, error) {
@@ -35,22 +52,58 @@ func Parse(data []byte) (*Exif, error) {
 		return nil, err
 	}
 
-	return &Exif{*t, unread}, nil
+	return &Exif{Tiff: *t}, nil
+}
+
+// ParseReaderAt parses size bytes from r as EXIF data or returns an
+// error. Values are read from r as they are requested.
+func ParseReaderAt(r io.ReaderAt, size int64) (*Exif, error) {
+	t, err := tiff.ParseReaderAt(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Exif{Tiff: *t}, nil
+}
+
+// Assemble returns EXIF data assembled from separately stored TIFF
+// structures, as found in CR3 files. The first IFD of exifIFD and gps
+// is added to the tree of ifd0 as the Exif IFD and the GPS IFD
+// respectively, and the first IFD of makerNote as the MakerNote of the
+// Exif IFD. exifIFD, gps and makerNote can be nil if not present.
+// ifd0 itself is left untouched.
+func Assemble(ifd0 *tiff.Tiff, exifIFD *tiff.Tiff, gps *tiff.Tiff, makerNote *tiff.Tiff) *Exif {
+	e := &Exif{
+		Tiff:      *ifd0,
+		makerNote: makerNote,
+	}
+
+	e.Graft("Exif", exifIFD)
+	e.Graft("GPS", gps)
+	_ = e.GraftAt(ExifPath, "MakerNote", makerNote)
+
+	return e
+}
+
+// MakerNoteTiff returns the MakerNote as a TIFF structure if it was
+// stored separately from the rest of the EXIF data, as in CR3 files.
+// If not, ErrTagNotFound is returned.
+func (e *Exif) MakerNoteTiff() (*tiff.Tiff, error) {
+	if e.makerNote == nil {
+		return nil, tiff.ErrTagNotFound
+	}
+
+	return e.makerNote, nil
 }
 
 // Entry returns the entry for the given IFD and tag or returns an
-// error. AnyIDF can be used to search all IFDs.
+// error. AnyIDF can be used to search the Exif IFD and all IFDs of
+// the main chain.
 func (e *Exif) Entry(ifd int, tag Tag) (tiff.Entry, error) {
 	if ifd == AnyIFD {
-		if e.exifIDFPointer == unread {
-			entry, err := e.Tiff.Entry(AnyIFD, tiff.ExifIDFPointer)
-			if err == nil {
-				*e.exifIDFPointer, _ = e.Tiff.ReadIFD(entry.Offset())
-			}
-		}
-
-		if *e.exifIDFPointer != nil {
-			entry, err := e.exifIDFPointer.Entry(tiff.Tag(tag))
+		node, err := e.Node(ExifPath)
+		if err == nil {
+			entry, err := node.Entries.Entry(tiff.Tag(tag))
 			if err == nil {
 				return entry, nil
 			}
@@ -62,7 +115,8 @@ func (e *Exif) Entry(ifd int, tag Tag) (tiff.Entry, error) {
 
 // Time returns the time for the given IFD and tag or returns
 // an error. Since EXIF carries no timezone information, the
-// location must be passed to set the timezone.
+// location must be passed to set the timezone. Blank times, like
+// "    :  :     :  :  ", are reported as ErrTagNotFound.
 func (e *Exif) Time(ifd int, tag Tag, loc *time.Location) (time.Time, error) {
 	entry, err := e.Entry(ifd, tag)
 	if err != nil {
@@ -74,10 +128,33 @@ func (e *Exif) Time(ifd int, tag Tag, loc *time.Location) (time.Time, error) {
 		return time.Time{}, err
 	}
 
+	if isBlankTime(str) {
+		return time.Time{}, ErrTagNotFound
+	}
+
 	return time.ParseInLocation("2006:01:02 15:04:05", str, loc)
 }
 
+// isBlankTime returns true for datetime values holding no time. Cameras
+// without a set clock write spaces or zeros in place of the digits.
+func isBlankTime(str string) bool {
+	for _, c := range str {
+		switch c {
+		case ' ', ':', '0', '-', '\x00':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseSubSecTime parses a SubSecTime value. The value holds the
+// decimal digits of the fraction of a second, so "5" is 500ms and
+// "005" is 5ms. Digits beyond nanoseconds are ignored.
 func parseSubSecTime(str string) (time.Duration, error) {
+	str = strings.TrimRight(str, " \x00")
+
 	if len(str) == 0 {
 		return 0, nil
 	}
@@ -86,40 +163,61 @@ func parseSubSecTime(str string) (time.Duration, error) {
 		str = str[:9]
 	}
 
-	n, err := strconv.Atoi(str)
+	n, err := strconv.ParseUint(str, 10, 32)
 	if err != nil {
 		return 0, err
 	}
 
-	return time.Duration(n) * time.Microsecond, nil
-}
+	for i := len(str); i < 9; i++ {
+		n *= 10
+	}
 
-// Time returns the "DateTimeOriginal" time or returns an error.
-func (e *Exif) TimeOriginal(loc *time.Location) (time.Time, error) {
-	var (
-		t   time.Time
-		err error
-	)
+	return time.Duration(n), nil
+}
 
-	t, err = e.Time(AnyIFD, DateTimeOriginal, loc)
+// dateTime returns the time of the datetime tag with its sub second
+// tag applied.
+func (e *Exif) dateTime(tags dateTimeTag, loc *time.Location) (time.Time, error) {
+	t, err := e.Time(AnyIFD, tags.dateTime, loc)
 	if err != nil {
 		return time.Time{}, err
 	}
 
-	entry, err := e.Entry(AnyIFD, SubSecTimeOriginal)
+	str, err := e.Ascii(AnyIFD, tags.subSec)
 	if err == nil {
-		str, err := entry.Ascii()
+		n, err := parseSubSecTime(str)
 		if err == nil {
-			n, err := parseSubSecTime(str)
-			if err == nil {
-				t = t.Add(time.Duration(n))
-			}
+			t = t.Add(n)
 		}
 	}
 
 	return t, nil
 }
 
+// DateTime returns the time the file was last changed, with
+// SubSecTime applied.
+func (e *Exif) DateTime(loc *time.Location) (time.Time, error) {
+	return e.dateTime(modifiedTags, loc)
+}
+
+// DateTimeOriginal returns the time the image was captured, with
+// SubSecTimeOriginal applied.
+func (e *Exif) DateTimeOriginal(loc *time.Location) (time.Time, error) {
+	return e.dateTime(originalTags, loc)
+}
+
+// DateTimeDigitized returns the time the image was stored as digital
+// data, with SubSecTimeDigitized applied.
+func (e *Exif) DateTimeDigitized(loc *time.Location) (time.Time, error) {
+	return e.dateTime(digitizedTags, loc)
+}
+
+// TimeOriginal returns the "DateTimeOriginal" time or returns an
+// error. It's the same as DateTimeOriginal.
+func (e *Exif) TimeOriginal(loc *time.Location) (time.Time, error) {
+	return e.DateTimeOriginal(loc)
+}
+
 // Ascii returns the ASCII value for the given IFD and tag or returns
 // an error. AnyIFD can be used to search all IFDs.
 func (e *Exif) Ascii(ifd int, tag Tag) (string, error) {
@@ -225,6 +323,23 @@ func (e *Exif) ExposureTime() (time.Duration, error) {
 	return time.Duration(r.Float() * float64(time.Second)), nil
 }
 
+// InteropIndex returns the interoperability index from the
+// Interoperability IFD, like "R98" for DCF basic files, "THM" for DCF
+// thumbnails or "R03" for Adobe RGB files.
+func (e *Exif) InteropIndex() (string, error) {
+	node, err := e.Node(InteropPath)
+	if err != nil {
+		return "", err
+	}
+
+	entry, err := node.Entries.Entry(tiff.Tag(InteroperabilityIndex))
+	if err != nil {
+		return "", err
+	}
+
+	return entry.Ascii()
+}
+
 // MakeModel returns the make and model of the camera or returns an
 // error. The make will be as "normalized" as possible, ie "Eastman
 // Kodak Company" will be translated to "Kodak".
@@ -249,3 +364,66 @@ func (e *Exif) MakeModel() (string, string, error) {
 
 	return make, model, nil
 }
+
+// XMP returns the XMP packet stored in the XMLPacket tag of IFD0. If
+// not found, xmp.ErrNoXMPFound is returned.
+func (e *Exif) XMP() ([]byte, error) {
+	entry, err := e.Tiff.Entry(0, tiff.XMLPacket)
+	if err != nil {
+		return nil, xmp.ErrNoXMPFound
+	}
+
+	return entry.Bytes()
+}
+
+// ImageResources returns the Photoshop image resources stored in the
+// ImageResources tag of IFD0.
+func (e *Exif) ImageResources() (psirb.Resources, error) {
+	entry, err := e.Tiff.Entry(0, tiff.ImageResources)
+	if err != nil {
+		return nil, psirb.ErrNoResourcesFound
+	}
+
+	data, err := entry.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return psirb.Parse(data)
+}
+
+// IPTC returns the IPTC data from the image resources, or from the
+// IPTCNAA tag of IFD0 if no resources are present. IPTCNAA is often
+// written as Long, so the raw bytes are used regardless of type.
+func (e *Exif) IPTC() (*iptc.IPTC, error) {
+	resources, err := e.ImageResources()
+	if err == nil || len(resources) > 0 {
+		i, err := resources.IPTC()
+		if err == nil {
+			return i, nil
+		}
+	}
+
+	entry, err := e.Tiff.Entry(0, tiff.IPTCNAA)
+	if err != nil {
+		return nil, iptc.ErrNoIPTCFound
+	}
+
+	data, err := entry.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return iptc.Parse(data)
+}
+
+// ICCProfile returns the ICC profile stored in the InterColorProfile
+// tag of IFD0.
+func (e *Exif) ICCProfile() ([]byte, error) {
+	entry, err := e.Tiff.Entry(0, tiff.InterColorProfile)
+	if err != nil {
+		return nil, icc.ErrNoICCFound
+	}
+
+	return entry.Bytes()
+}
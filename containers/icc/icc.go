@@ -0,0 +1,275 @@
+package icc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+var (
+	// ErrNoICCFound is returned if a file carries no ICC profile.
+	ErrNoICCFound = errors.New("no ICC profile found")
+
+	// ErrMalformed is returned if the profile header or tag table is
+	// invalid.
+	ErrMalformed = errors.New("malformed ICC profile")
+
+	// ErrTagNotFound is returned if a tag is not present in the
+	// profile.
+	ErrTagNotFound = errors.New("tag not found")
+
+	// ErrUnsupportedType is returned if a tag has a type that can't be
+	// decoded as text.
+	ErrUnsupportedType = errors.New("unsupported tag type")
+)
+
+const (
+	headerSize = 128
+
+	// signature is found at offset 36 in all profiles.
+	signature = "acsp"
+)
+
+// Tag signatures for text tags.
+const (
+	DescriptionTag = "desc"
+	CopyrightTag   = "cprt"
+)
+
+// TagEntry is an entry in the tag table.
+type TagEntry struct {
+	Signature string
+	Offset    uint32
+	Size      uint32
+}
+
+// Profile is a parsed ICC profile header and tag table. Tag data is
+// decoded on request.
+type Profile struct {
+	Size         uint32
+	CMM          string
+	Version      Version
+	Class        string
+	ColorSpace   ColorSpace
+	PCS          ColorSpace
+	Created      time.Time
+	Platform     string
+	Manufacturer string
+	Model        string
+	Intent       uint32
+	Creator      string
+	Tags         []TagEntry
+
+	data []byte
+}
+
+// Version is the profile version.
+type Version struct {
+	Major  uint8
+	Minor  uint8
+	Bugfix uint8
+}
+
+// String returns the version as "major.minor.bugfix".
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Bugfix)
+}
+
+// ColorSpace is a colour space signature, like "RGB " or "GRAY".
+type ColorSpace string
+
+// Colour space signatures.
+const (
+	XYZ  ColorSpace = "XYZ "
+	Lab  ColorSpace = "Lab "
+	RGB  ColorSpace = "RGB "
+	Gray ColorSpace = "GRAY"
+	CMYK ColorSpace = "CMYK"
+	CMY  ColorSpace = "CMY "
+	YCbr ColorSpace = "YCbr"
+)
+
+// String returns the signature without padding.
+func (c ColorSpace) String() string {
+	return strings.TrimRight(string(c), " ")
+}
+
+// Parse parses the header and tag table of an ICC profile.
+func Parse(data []byte) (*Profile, error) {
+	if len(data) < headerSize+4 || string(data[36:40]) != signature {
+		return nil, ErrMalformed
+	}
+
+	p := &Profile{
+		Size:         binary.BigEndian.Uint32(data[0:4]),
+		CMM:          signatureString(data[4:8]),
+		Version:      Version{Major: data[8], Minor: data[9] >> 4, Bugfix: data[9] & 0x0f},
+		Class:        signatureString(data[12:16]),
+		ColorSpace:   ColorSpace(data[16:20]),
+		PCS:          ColorSpace(data[20:24]),
+		Created:      parseDateTime(data[24:36]),
+		Platform:     signatureString(data[40:44]),
+		Manufacturer: signatureString(data[48:52]),
+		Model:        signatureString(data[52:56]),
+		Intent:       binary.BigEndian.Uint32(data[64:68]),
+		Creator:      signatureString(data[80:84]),
+		data:         data,
+	}
+
+	count := int(binary.BigEndian.Uint32(data[headerSize:]))
+	if count > (len(data)-headerSize-4)/12 {
+		return nil, ErrMalformed
+	}
+
+	for i := 0; i < count; i++ {
+		entry := data[headerSize+4+12*i:]
+
+		p.Tags = append(p.Tags, TagEntry{
+			Signature: string(entry[0:4]),
+			Offset:    binary.BigEndian.Uint32(entry[4:8]),
+			Size:      binary.BigEndian.Uint32(entry[8:12]),
+		})
+	}
+
+	return p, nil
+}
+
+// signatureString returns a four byte signature with padding removed.
+// Unset signatures are returned as an empty string.
+func signatureString(data []byte) string {
+	return strings.TrimRight(string(data), " \000")
+}
+
+func parseDateTime(data []byte) time.Time {
+	var v [6]int
+
+	for i := range v {
+		v[i] = int(binary.BigEndian.Uint16(data[2*i:]))
+	}
+
+	if v[0] == 0 {
+		return time.Time{}
+	}
+
+	return time.Date(v[0], time.Month(v[1]), v[2], v[3], v[4], v[5], 0, time.UTC)
+}
+
+// Tag returns the raw data of the tag with the given signature,
+// including the type signature.
+func (p *Profile) Tag(sig string) ([]byte, error) {
+	for _, t := range p.Tags {
+		if t.Signature != sig {
+			continue
+		}
+
+		end := uint64(t.Offset) + uint64(t.Size)
+		if t.Size < 8 || end > uint64(len(p.data)) {
+			return nil, ErrMalformed
+		}
+
+		return p.data[t.Offset:end], nil
+	}
+
+	return nil, ErrTagNotFound
+}
+
+// Text returns the tag as text. Tags of the types textType,
+// textDescriptionType (version 2) and multiLocalizedUnicodeType
+// (version 4) are supported. For localized text the English text is
+// preferred.
+func (p *Profile) Text(sig string) (string, error) {
+	data, err := p.Tag(sig)
+	if err != nil {
+		return "", err
+	}
+
+	switch string(data[0:4]) {
+	case "text":
+		return cString(data[8:]), nil
+
+	case "desc":
+		if len(data) < 12 {
+			return "", ErrMalformed
+		}
+
+		length := binary.BigEndian.Uint32(data[8:12])
+		if uint64(length) > uint64(len(data)-12) {
+			return "", ErrMalformed
+		}
+
+		return cString(data[12 : 12+length]), nil
+
+	case "mluc":
+		return parseMluc(data)
+
+	default:
+		return "", ErrUnsupportedType
+	}
+}
+
+// Description returns the profile description, like "sRGB IEC61966-2.1"
+// or "Display P3".
+func (p *Profile) Description() (string, error) {
+	return p.Text(DescriptionTag)
+}
+
+// Copyright returns the profile copyright.
+func (p *Profile) Copyright() (string, error) {
+	return p.Text(CopyrightTag)
+}
+
+// cString returns data up to the first NUL byte.
+func cString(data []byte) string {
+	for i, b := range data {
+		if b == 0 {
+			return string(data[:i])
+		}
+	}
+
+	return string(data)
+}
+
+// parseMluc returns the English record of a multiLocalizedUnicodeType,
+// or the first record if there's no English record.
+func parseMluc(data []byte) (string, error) {
+	if len(data) < 16 {
+		return "", ErrMalformed
+	}
+
+	count := int(binary.BigEndian.Uint32(data[8:12]))
+	recordSize := int(binary.BigEndian.Uint32(data[12:16]))
+
+	if count == 0 || recordSize < 12 || count > (len(data)-16)/recordSize {
+		return "", ErrMalformed
+	}
+
+	chosen := 0
+
+	for i := 0; i < count; i++ {
+		if string(data[16+i*recordSize:18+i*recordSize]) == "en" {
+			chosen = i
+
+			break
+		}
+	}
+
+	record := data[16+chosen*recordSize:]
+	length := uint64(binary.BigEndian.Uint32(record[4:8]))
+	offset := uint64(binary.BigEndian.Uint32(record[8:12]))
+
+	if offset+length > uint64(len(data)) {
+		return "", ErrMalformed
+	}
+
+	text := data[offset : offset+length]
+
+	units := make([]uint16, len(text)/2)
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(text[2*i:])
+	}
+
+	return strings.TrimRight(string(utf16.Decode(units)), "\000"), nil
+}
@@ -0,0 +1,150 @@
+package iptc
+
+import (
+	"fmt"
+)
+
+// Tag identifies an IPTC-IIM dataset. The record number is in the high
+// byte and the dataset number in the low byte, so 2:25 is 0x0219.
+type Tag uint16
+
+var _ fmt.Stringer = Tag(0)
+
+const (
+	// Envelope record.
+	ModelVersion      Tag = 0x0100
+	Destination       Tag = 0x0105
+	FileFormat        Tag = 0x0114
+	FileFormatVersion Tag = 0x0116
+	ServiceIdentifier Tag = 0x011E
+	EnvelopeNumber    Tag = 0x0128
+	ProductID         Tag = 0x0132
+	EnvelopePriority  Tag = 0x013C
+	DateSent          Tag = 0x0146
+	TimeSent          Tag = 0x0150
+	CodedCharacterSet Tag = 0x015A
+	UNO               Tag = 0x0164
+
+	// Application record.
+	RecordVersion                 Tag = 0x0200
+	ObjectTypeReference           Tag = 0x0203
+	ObjectName                    Tag = 0x0205
+	EditStatus                    Tag = 0x0207
+	Urgency                       Tag = 0x020A
+	SubjectReference              Tag = 0x020C
+	Category                      Tag = 0x020F
+	SupplementalCategory          Tag = 0x0214
+	FixtureIdentifier             Tag = 0x0216
+	Keywords                      Tag = 0x0219
+	ContentLocationCode           Tag = 0x021A
+	ContentLocationName           Tag = 0x021B
+	ReleaseDate                   Tag = 0x021E
+	ReleaseTime                   Tag = 0x0223
+	ExpirationDate                Tag = 0x0225
+	ExpirationTime                Tag = 0x0226
+	SpecialInstructions           Tag = 0x0228
+	ActionAdvised                 Tag = 0x022A
+	ReferenceService              Tag = 0x022D
+	ReferenceDate                 Tag = 0x022F
+	ReferenceNumber               Tag = 0x0232
+	DateCreated                   Tag = 0x0237
+	TimeCreated                   Tag = 0x023C
+	DigitalCreationDate           Tag = 0x023E
+	DigitalCreationTime           Tag = 0x023F
+	OriginatingProgram            Tag = 0x0241
+	ProgramVersion                Tag = 0x0246
+	ObjectCycle                   Tag = 0x024B
+	Byline                        Tag = 0x0250
+	BylineTitle                   Tag = 0x0255
+	City                          Tag = 0x025A
+	SubLocation                   Tag = 0x025C
+	ProvinceState                 Tag = 0x025F
+	CountryCode                   Tag = 0x0264
+	Country                       Tag = 0x0265
+	OriginalTransmissionReference Tag = 0x0267
+	Headline                      Tag = 0x0269
+	Credit                        Tag = 0x026E
+	Source                        Tag = 0x0273
+	CopyrightNotice               Tag = 0x0274
+	Contact                       Tag = 0x0276
+	Caption                       Tag = 0x0278
+	CaptionWriter                 Tag = 0x027A
+)
+
+// Record returns the record number of the tag.
+func (t Tag) Record() uint8 {
+	return uint8(t >> 8)
+}
+
+// DataSet returns the dataset number of the tag.
+func (t Tag) DataSet() uint8 {
+	return uint8(t)
+}
+
+// String returns a string representation of the tag.
+func (t Tag) String() string {
+	m := map[Tag]string{
+		ModelVersion:      "ModelVersion",
+		Destination:       "Destination",
+		FileFormat:        "FileFormat",
+		FileFormatVersion: "FileFormatVersion",
+		ServiceIdentifier: "ServiceIdentifier",
+		EnvelopeNumber:    "EnvelopeNumber",
+		ProductID:         "ProductID",
+		EnvelopePriority:  "EnvelopePriority",
+		DateSent:          "DateSent",
+		TimeSent:          "TimeSent",
+		CodedCharacterSet: "CodedCharacterSet",
+		UNO:               "UNO",
+
+		RecordVersion:                 "RecordVersion",
+		ObjectTypeReference:           "ObjectTypeReference",
+		ObjectName:                    "ObjectName",
+		EditStatus:                    "EditStatus",
+		Urgency:                       "Urgency",
+		SubjectReference:              "SubjectReference",
+		Category:                      "Category",
+		SupplementalCategory:          "SupplementalCategory",
+		FixtureIdentifier:             "FixtureIdentifier",
+		Keywords:                      "Keywords",
+		ContentLocationCode:           "ContentLocationCode",
+		ContentLocationName:           "ContentLocationName",
+		ReleaseDate:                   "ReleaseDate",
+		ReleaseTime:                   "ReleaseTime",
+		ExpirationDate:                "ExpirationDate",
+		ExpirationTime:                "ExpirationTime",
+		SpecialInstructions:           "SpecialInstructions",
+		ActionAdvised:                 "ActionAdvised",
+		ReferenceService:              "ReferenceService",
+		ReferenceDate:                 "ReferenceDate",
+		ReferenceNumber:               "ReferenceNumber",
+		DateCreated:                   "DateCreated",
+		TimeCreated:                   "TimeCreated",
+		DigitalCreationDate:           "DigitalCreationDate",
+		DigitalCreationTime:           "DigitalCreationTime",
+		OriginatingProgram:            "OriginatingProgram",
+		ProgramVersion:                "ProgramVersion",
+		ObjectCycle:                   "ObjectCycle",
+		Byline:                        "Byline",
+		BylineTitle:                   "BylineTitle",
+		City:                          "City",
+		SubLocation:                   "SubLocation",
+		ProvinceState:                 "ProvinceState",
+		CountryCode:                   "CountryCode",
+		Country:                       "Country",
+		OriginalTransmissionReference: "OriginalTransmissionReference",
+		Headline:                      "Headline",
+		Credit:                        "Credit",
+		Source:                        "Source",
+		CopyrightNotice:               "CopyrightNotice",
+		Contact:                       "Contact",
+		Caption:                       "Caption",
+		CaptionWriter:                 "CaptionWriter",
+	}
+
+	if s, ok := m[t]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("UNKNOWN:%d:%d", t.Record(), t.DataSet())
+}
@@ -0,0 +1,239 @@
+package iptc
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrNoIPTCFound is returned if no IPTC data is found.
+	ErrNoIPTCFound = errors.New("no IPTC data found")
+
+	// ErrMalformed is returned if the data doesn't start with a dataset
+	// marker or a dataset is truncated.
+	ErrMalformed = errors.New("malformed IPTC data")
+
+	// ErrDataSetNotFound is returned if a dataset is not present.
+	ErrDataSetNotFound = errors.New("dataset not found")
+)
+
+// marker starts every dataset.
+const marker = 0x1C
+
+// utf8Escape is the ISO 2022 escape sequence for UTF-8, as used in
+// CodedCharacterSet.
+var utf8Escape = []byte{0x1B, '%', 'G'}
+
+// DataSet is a single IPTC-IIM dataset.
+type DataSet struct {
+	Tag  Tag
+	Data []byte
+}
+
+// IPTC is parsed IPTC-IIM data.
+type IPTC struct {
+	// DataSets are the datasets in file order. Repeatable datasets,
+	// like keywords, appear once per value.
+	DataSets []DataSet
+
+	utf8 bool
+}
+
+// Parse parses IPTC-IIM datasets. Parsing stops at the first byte not
+// starting a dataset, as the data is often followed by padding.
+func Parse(data []byte) (*IPTC, error) {
+	if len(data) == 0 || data[0] != marker {
+		return nil, ErrMalformed
+	}
+
+	i := &IPTC{}
+
+	offset := 0
+
+	for offset < len(data) && data[offset] == marker {
+		if offset+5 > len(data) {
+			return nil, ErrMalformed
+		}
+
+		tag := Tag(uint16(data[offset+1])<<8 | uint16(data[offset+2]))
+		length := int(data[offset+3])<<8 | int(data[offset+4])
+		offset += 5
+
+		// Extended datasets store the number of length bytes in
+		// the lower 15 bits.
+		if length&0x8000 != 0 {
+			n := length & 0x7FFF
+			if n > 4 || offset+n > len(data) {
+				return nil, ErrMalformed
+			}
+
+			length = 0
+			for _, b := range data[offset : offset+n] {
+				length = length<<8 | int(b)
+			}
+
+			offset += n
+		}
+
+		if length < 0 || length > len(data)-offset {
+			return nil, ErrMalformed
+		}
+
+		value := data[offset : offset+length]
+		offset += length
+
+		if tag == CodedCharacterSet && bytes.Equal(value, utf8Escape) {
+			i.utf8 = true
+		}
+
+		i.DataSets = append(i.DataSets, DataSet{Tag: tag, Data: value})
+	}
+
+	return i, nil
+}
+
+// UTF8 returns true if the CodedCharacterSet declares UTF-8.
+func (i *IPTC) UTF8() bool {
+	return i.utf8
+}
+
+// decode returns data as a string. Without a declared character set,
+// data that isn't valid UTF-8 is assumed to be ISO 8859-1.
+func (i *IPTC) decode(data []byte) string {
+	if i.utf8 || utf8.Valid(data) {
+		return string(data)
+	}
+
+	var b strings.Builder
+
+	for _, c := range data {
+		b.WriteRune(rune(c))
+	}
+
+	return b.String()
+}
+
+// Raw returns the data of all datasets with the given tag.
+func (i *IPTC) Raw(tag Tag) [][]byte {
+	var values [][]byte
+
+	for _, d := range i.DataSets {
+		if d.Tag == tag {
+			values = append(values, d.Data)
+		}
+	}
+
+	return values
+}
+
+// String returns the first value of the given tag or
+// ErrDataSetNotFound.
+func (i *IPTC) String(tag Tag) (string, error) {
+	for _, d := range i.DataSets {
+		if d.Tag == tag {
+			return i.decode(d.Data), nil
+		}
+	}
+
+	return "", ErrDataSetNotFound
+}
+
+// Strings returns all values of a repeatable tag or
+// ErrDataSetNotFound.
+func (i *IPTC) Strings(tag Tag) ([]string, error) {
+	raw := i.Raw(tag)
+	if len(raw) == 0 {
+		return nil, ErrDataSetNotFound
+	}
+
+	values := make([]string, len(raw))
+	for j, data := range raw {
+		values[j] = i.decode(data)
+	}
+
+	return values, nil
+}
+
+// ObjectName returns the object name, usually a short title.
+func (i *IPTC) ObjectName() (string, error) {
+	return i.String(ObjectName)
+}
+
+// Headline returns the headline.
+func (i *IPTC) Headline() (string, error) {
+	return i.String(Headline)
+}
+
+// Caption returns the caption/abstract.
+func (i *IPTC) Caption() (string, error) {
+	return i.String(Caption)
+}
+
+// Bylines returns the names of the creators.
+func (i *IPTC) Bylines() ([]string, error) {
+	return i.Strings(Byline)
+}
+
+// Keywords returns the keywords.
+func (i *IPTC) Keywords() ([]string, error) {
+	return i.Strings(Keywords)
+}
+
+// Credit returns the credit line.
+func (i *IPTC) Credit() (string, error) {
+	return i.String(Credit)
+}
+
+// Source returns the original owner of the content.
+func (i *IPTC) Source() (string, error) {
+	return i.String(Source)
+}
+
+// Copyright returns the copyright notice.
+func (i *IPTC) Copyright() (string, error) {
+	return i.String(CopyrightNotice)
+}
+
+// City returns the city of the content.
+func (i *IPTC) City() (string, error) {
+	return i.String(City)
+}
+
+// Country returns the country name of the content.
+func (i *IPTC) Country() (string, error) {
+	return i.String(Country)
+}
+
+// DateCreated returns the date and time the content was created,
+// combining DateCreated (CCYYMMDD) and TimeCreated (HHMMSS±HHMM). If
+// the time is missing or lacks a timezone, loc is used.
+func (i *IPTC) DateCreated(loc *time.Location) (time.Time, error) {
+	return i.Time(DateCreated, TimeCreated, loc)
+}
+
+// Time returns the time from a pair of date and time datasets. See
+// DateCreated.
+func (i *IPTC) Time(dateTag Tag, timeTag Tag, loc *time.Location) (time.Time, error) {
+	date, err := i.String(dateTag)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	date = strings.TrimSpace(date)
+
+	clock, err := i.String(timeTag)
+	if err != nil {
+		return time.ParseInLocation("20060102", date, loc)
+	}
+
+	clock = strings.TrimSpace(clock)
+
+	if len(clock) > 6 {
+		return time.Parse("20060102150405-0700", date+clock)
+	}
+
+	return time.ParseInLocation("20060102150405", date+clock, loc)
+}
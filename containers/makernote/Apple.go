@@ -0,0 +1,216 @@
+package makernote
+
+import (
+	"fmt"
+
+	"github.com/abrander/apexif/containers/bplist"
+	"github.com/abrander/apexif/containers/tiff"
+)
+
+// Apple MakerNote tags.
+const (
+	AppleVersionTag             tiff.Tag = 0x0001
+	AppleRunTimeTag             tiff.Tag = 0x0003
+	AppleAccelerationVectorTag  tiff.Tag = 0x0008
+	AppleHDRImageTypeTag        tiff.Tag = 0x000a
+	AppleBurstUUIDTag           tiff.Tag = 0x000b
+	AppleContentIdentifierTag   tiff.Tag = 0x0011
+	AppleImageCaptureTypeTag    tiff.Tag = 0x0014
+	AppleImageUniqueIDTag       tiff.Tag = 0x0015
+	AppleLivePhotoVideoIndexTag tiff.Tag = 0x0017
+)
+
+// Apple is a decoded Apple iOS MakerNote.
+type Apple struct {
+	Version int
+
+	// RunTime is the time since the device booted when the image was
+	// captured. It's nil if not present.
+	RunTime *AppleRunTime
+
+	// AccelerationVector is the acceleration of the device in g along
+	// the x, y and z axes. The axes are relative to the device.
+	AccelerationVector [3]float64
+
+	HDRImageType AppleHDRImageType
+
+	// BurstUUID is shared by all images of a burst.
+	BurstUUID string
+
+	// ContentIdentifier is shared by a Live Photo still and its video,
+	// where it's stored as com.apple.quicktime.content.identifier.
+	ContentIdentifier string
+
+	ImageCaptureType AppleImageCaptureType
+
+	ImageUniqueID string
+
+	LivePhotoVideoIndex int
+}
+
+// AppleRunTime is a CMTime, stored as a binary property list.
+type AppleRunTime struct {
+	Flags     int64
+	Value     int64
+	Timescale int64
+	Epoch     int64
+}
+
+// Seconds returns the run time in seconds.
+func (r *AppleRunTime) Seconds() float64 {
+	if r.Timescale == 0 {
+		return 0
+	}
+
+	return float64(r.Value) / float64(r.Timescale)
+}
+
+// AppleHDRImageType tells if the image is an HDR image.
+type AppleHDRImageType int
+
+const (
+	AppleHDRImage      AppleHDRImageType = 3
+	AppleOriginalImage AppleHDRImageType = 4
+)
+
+var _ fmt.Stringer = AppleHDRImageType(0)
+
+// String returns a string representation of the HDR image type.
+func (t AppleHDRImageType) String() string {
+	switch t {
+	case AppleHDRImage:
+		return "HDR Image"
+	case AppleOriginalImage:
+		return "Original Image"
+	default:
+		return fmt.Sprintf("Unknown (%d)", int(t))
+	}
+}
+
+// AppleImageCaptureType is the kind of capture.
+type AppleImageCaptureType int
+
+const (
+	AppleCaptureProRAW      AppleImageCaptureType = 1
+	AppleCapturePortrait    AppleImageCaptureType = 2
+	AppleCapturePhoto       AppleImageCaptureType = 10
+	AppleCaptureManualFocus AppleImageCaptureType = 11
+	AppleCaptureScene       AppleImageCaptureType = 12
+)
+
+var _ fmt.Stringer = AppleImageCaptureType(0)
+
+// String returns a string representation of the capture type.
+func (t AppleImageCaptureType) String() string {
+	names := map[AppleImageCaptureType]string{
+		AppleCaptureProRAW:      "ProRAW",
+		AppleCapturePortrait:    "Portrait",
+		AppleCapturePhoto:       "Photo",
+		AppleCaptureManualFocus: "Manual Focus",
+		AppleCaptureScene:       "Scene",
+	}
+
+	if s, ok := names[t]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Unknown (%d)", int(t))
+}
+
+// HDR returns true if the image is an HDR image.
+func (a *Apple) HDR() bool {
+	return a.HDRImageType == AppleHDRImage
+}
+
+// Portrait returns true if the image was captured in portrait mode.
+func (a *Apple) Portrait() bool {
+	return a.ImageCaptureType == AppleCapturePortrait
+}
+
+// LivePhoto returns true if the image has a content identifier linking
+// it to a Live Photo video.
+func (a *Apple) LivePhoto() bool {
+	return a.ContentIdentifier != ""
+}
+
+// Burst returns true if the image is part of a burst.
+func (a *Apple) Burst() bool {
+	return a.BurstUUID != ""
+}
+
+// Apple decodes the MakerNote as an Apple MakerNote.
+func (m *MakerNote) Apple() (*Apple, error) {
+	if m.Vendor != VendorApple {
+		return nil, ErrUnknownVendor
+	}
+
+	a := &Apple{}
+
+	a.Version, _ = m.integer(AppleVersionTag)
+	a.LivePhotoVideoIndex, _ = m.integer(AppleLivePhotoVideoIndexTag)
+	a.BurstUUID, _ = m.Tiff.Ascii(0, AppleBurstUUIDTag)
+	a.ContentIdentifier, _ = m.Tiff.Ascii(0, AppleContentIdentifierTag)
+	a.ImageUniqueID, _ = m.Tiff.Ascii(0, AppleImageUniqueIDTag)
+
+	if v, err := m.integer(AppleHDRImageTypeTag); err == nil {
+		a.HDRImageType = AppleHDRImageType(v)
+	}
+
+	if v, err := m.integer(AppleImageCaptureTypeTag); err == nil {
+		a.ImageCaptureType = AppleImageCaptureType(v)
+	}
+
+	if e, err := m.Entry(AppleAccelerationVectorTag); err == nil {
+		r, err := e.SRationalSlice()
+		if err == nil && len(r) == 3 {
+			for i := range r {
+				if r[i].Denominator != 0 {
+					a.AccelerationVector[i] = r[i].Float()
+				}
+			}
+		}
+	}
+
+	if data, err := m.bytes(AppleRunTimeTag); err == nil {
+		a.RunTime = parseAppleRunTime(data)
+	}
+
+	return a, nil
+}
+
+// integer returns the value of a single integer entry of any type.
+func (m *MakerNote) integer(tag tiff.Tag) (int, error) {
+	e, err := m.Entry(tag)
+	if err != nil {
+		return 0, err
+	}
+
+	return e.Int()
+}
+
+// parseAppleRunTime decodes the binary property list holding the run
+// time. nil is returned if it can't be decoded.
+func parseAppleRunTime(data []byte) *AppleRunTime {
+	v, err := bplist.Parse(data)
+	if err != nil {
+		return nil
+	}
+
+	dict, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	value := func(key string) int64 {
+		i, _ := dict[key].(int64)
+
+		return i
+	}
+
+	return &AppleRunTime{
+		Flags:     value("flags"),
+		Value:     value("value"),
+		Timescale: value("timescale"),
+		Epoch:     value("epoch"),
+	}
+}
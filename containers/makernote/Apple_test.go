@@ -0,0 +1,34 @@
+package makernote
+
+import "testing"
+
+func TestParseAppleRunTime(t *testing.T) {
+	// {"epoch": 0, "flags": 1, "timescale": 1000000000, "value":
+	// 123456789} written by Python's plistlib.
+	data := []byte("" +
+		"\x62\x70\x6c\x69\x73\x74\x30\x30\xd4\x01\x02\x03\x04\x05\x06\x07" +
+		"\x08\x55\x65\x70\x6f\x63\x68\x55\x66\x6c\x61\x67\x73\x59\x74\x69" +
+		"\x6d\x65\x73\x63\x61\x6c\x65\x55\x76\x61\x6c\x75\x65\x10\x00\x10" +
+		"\x01\x12\x3b\x9a\xca\x00\x12\x07\x5b\xcd\x15\x08\x11\x17\x1d\x27" +
+		"\x2d\x2f\x31\x36\x00\x00\x00\x00\x00\x00\x01\x01\x00\x00\x00\x00" +
+		"\x00\x00\x00\x09\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00" +
+		"\x00\x00\x00\x3b")
+
+	r := parseAppleRunTime(data)
+	if r == nil {
+		t.Fatalf("failed to decode run time")
+	}
+
+	want := AppleRunTime{Flags: 1, Value: 123456789, Timescale: 1000000000}
+	if *r != want {
+		t.Errorf("got %+v, expected %+v", *r, want)
+	}
+
+	if s := r.Seconds(); s != 0.123456789 {
+		t.Errorf("got %g seconds, expected 0.123456789", s)
+	}
+
+	if r := parseAppleRunTime(data[:40]); r != nil {
+		t.Errorf("got %+v for a truncated list, expected nil", *r)
+	}
+}
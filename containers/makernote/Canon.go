@@ -0,0 +1,439 @@
+package makernote
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/abrander/apexif/containers/tiff"
+)
+
+// Canon MakerNote tags.
+const (
+	CanonCameraSettingsTag  tiff.Tag = 0x0001
+	CanonShotInfoTag        tiff.Tag = 0x0004
+	CanonImageTypeTag       tiff.Tag = 0x0006
+	CanonFirmwareVersionTag tiff.Tag = 0x0007
+	CanonFileNumberTag      tiff.Tag = 0x0008
+	CanonOwnerNameTag       tiff.Tag = 0x0009
+	CanonSerialNumberTag    tiff.Tag = 0x000c
+	CanonModelIDTag         tiff.Tag = 0x0010
+	CanonAFInfoTag          tiff.Tag = 0x0012
+	CanonAFInfo2Tag         tiff.Tag = 0x0026
+	CanonFileInfoTag        tiff.Tag = 0x0093
+	CanonLensModelTag       tiff.Tag = 0x0095
+	CanonInternalSerialTag  tiff.Tag = 0x0096
+)
+
+// Canon is a decoded Canon MakerNote. The same data is found in CR2,
+// CR3 and JPEG files as a MakerNote IFD, and in CRW files as heap
+// records. Sections not present are nil.
+type Canon struct {
+	ModelID              uint32
+	ImageType            string
+	FirmwareVersion      string
+	FileNumber           uint32
+	OwnerName            string
+	SerialNumber         string
+	InternalSerialNumber string
+
+	// LensModel is the name of the lens as recorded by the camera.
+	// Older cameras only record the lens ID, CameraSettings.LensType.
+	LensModel string
+
+	CameraSettings *CanonCameraSettings
+	ShotInfo       *CanonShotInfo
+	FileInfo       *CanonFileInfo
+	AFInfo         *CanonAFInfo
+}
+
+// CanonFocusMode is the focus mode of CanonCameraSettings.
+type CanonFocusMode uint16
+
+var _ fmt.Stringer = CanonFocusMode(0)
+
+// String returns a string representation of the focus mode.
+func (m CanonFocusMode) String() string {
+	names := map[CanonFocusMode]string{
+		0:   "One-shot AF",
+		1:   "AI Servo AF",
+		2:   "AI Focus AF",
+		3:   "Manual Focus",
+		4:   "Single",
+		5:   "Continuous",
+		6:   "Manual Focus",
+		16:  "Pan Focus",
+		256: "One-shot AF (Live View)",
+		257: "AI Servo AF (Live View)",
+		258: "AI Focus AF (Live View)",
+		512: "Movie Snap Focus",
+		519: "Movie Servo AF",
+	}
+
+	if s, ok := names[m]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Unknown (%d)", uint16(m))
+}
+
+// CanonDriveMode is the continuous drive setting of
+// CanonCameraSettings.
+type CanonDriveMode uint16
+
+var _ fmt.Stringer = CanonDriveMode(0)
+
+// String returns a string representation of the drive mode.
+func (m CanonDriveMode) String() string {
+	names := map[CanonDriveMode]string{
+		0:  "Single",
+		1:  "Continuous",
+		2:  "Movie",
+		3:  "Continuous, Speed Priority",
+		4:  "Continuous, Low",
+		5:  "Continuous, High",
+		6:  "Silent Single",
+		8:  "Continuous, High+",
+		9:  "Single, Silent",
+		10: "Continuous, Silent",
+	}
+
+	if s, ok := names[m]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Unknown (%d)", uint16(m))
+}
+
+// CanonCameraSettings is the CameraSettings record. Values are kept
+// as recorded, as their meaning varies between models.
+type CanonCameraSettings struct {
+	MacroMode      int16
+	SelfTimer      int16
+	Quality        int16
+	FlashMode      int16
+	DriveMode      CanonDriveMode
+	FocusMode      CanonFocusMode
+	RecordMode     int16
+	ImageSize      int16
+	EasyMode       int16
+	MeteringMode   int16
+	FocusRange     int16
+	ExposureMode   int16
+	LensType       uint16
+	MaxFocalLength uint16
+	MinFocalLength uint16
+	FocalUnits     uint16
+	MaxAperture    int16
+	MinAperture    int16
+
+	ImageStabilization int16
+}
+
+// FocalLengths returns the focal length range of the lens in mm.
+func (s *CanonCameraSettings) FocalLengths() (float64, float64) {
+	units := float64(s.FocalUnits)
+	if units == 0 {
+		units = 1
+	}
+
+	return float64(s.MinFocalLength) / units, float64(s.MaxFocalLength) / units
+}
+
+// CanonShotInfo is the ShotInfo record.
+type CanonShotInfo struct {
+	AutoISO        int16
+	BaseISO        int16
+	MeasuredEV     int16
+	TargetAperture int16
+	TargetExposure int16
+	ExposureComp   int16
+	WhiteBalance   int16
+	SequenceNumber int16
+
+	// CameraTemperature is in °C, and only recorded by EOS models.
+	CameraTemperature int16
+
+	// AFPointsInFocus is a bit mask used by models without AFInfo.
+	AFPointsInFocus uint16
+
+	// FocusDistanceUpper and FocusDistanceLower are in cm, with
+	// 65535 meaning infinity.
+	FocusDistanceUpper uint16
+	FocusDistanceLower uint16
+}
+
+// ISO returns the ISO speed computed from the base ISO and the auto
+// ISO factor.
+func (s *CanonShotInfo) ISO() float64 {
+	auto := math.Pow(2, float64(s.AutoISO)/32) * 100
+	base := math.Pow(2, float64(s.BaseISO)/32) * 100 / 32
+
+	return base * auto / 100
+}
+
+// CanonFileInfo is the FileInfo record.
+type CanonFileInfo struct {
+	// ShutterCount is only recorded by EOS-1D models, and is 0 for
+	// other models.
+	ShutterCount uint32
+
+	BracketMode       int16
+	BracketValue      int16
+	BracketShotNumber int16
+	LiveViewShooting  int16
+	ShutterMode       int16
+}
+
+// CanonAFPoint is an AF point of CanonAFInfo. Positions are relative
+// to the center of the AF image, with y pointing up.
+type CanonAFPoint struct {
+	Width    uint16
+	Height   uint16
+	X        int16
+	Y        int16
+	InFocus  bool
+	Selected bool
+}
+
+// CanonAFInfo is the AFInfo or AFInfo2 record.
+type CanonAFInfo struct {
+	// AreaMode is only recorded in AFInfo2.
+	AreaMode uint16
+
+	ValidPoints   uint16
+	ImageWidth    uint16
+	ImageHeight   uint16
+	AFImageWidth  uint16
+	AFImageHeight uint16
+	Points        []CanonAFPoint
+}
+
+// InFocus returns the indices of the AF points in focus.
+func (a *CanonAFInfo) InFocus() []int {
+	var points []int
+
+	for i, p := range a.Points {
+		if p.InFocus {
+			points = append(points, i)
+		}
+	}
+
+	return points
+}
+
+// words is an array record with zero values past the end.
+type words []uint16
+
+func (w words) at(i int) uint16 {
+	if i < len(w) {
+		return w[i]
+	}
+
+	return 0
+}
+
+func (w words) signed(i int) int16 {
+	return int16(w.at(i))
+}
+
+// bit returns bit i of the bit mask starting at word start.
+func (w words) bit(start int, i int) bool {
+	return w.at(start+i/16)&(1<<(i%16)) != 0
+}
+
+// ParseCanonCameraSettings decodes a CameraSettings record. The first
+// word is the size of the record.
+func ParseCanonCameraSettings(v []uint16) *CanonCameraSettings {
+	w := words(v)
+
+	return &CanonCameraSettings{
+		MacroMode:          w.signed(1),
+		SelfTimer:          w.signed(2),
+		Quality:            w.signed(3),
+		FlashMode:          w.signed(4),
+		DriveMode:          CanonDriveMode(w.at(5)),
+		FocusMode:          CanonFocusMode(w.at(7)),
+		RecordMode:         w.signed(9),
+		ImageSize:          w.signed(10),
+		EasyMode:           w.signed(11),
+		MeteringMode:       w.signed(17),
+		FocusRange:         w.signed(18),
+		ExposureMode:       w.signed(20),
+		LensType:           w.at(22),
+		MaxFocalLength:     w.at(23),
+		MinFocalLength:     w.at(24),
+		FocalUnits:         w.at(25),
+		MaxAperture:        w.signed(26),
+		MinAperture:        w.signed(27),
+		ImageStabilization: w.signed(34),
+	}
+}
+
+// ParseCanonShotInfo decodes a ShotInfo record. The first word is the
+// size of the record.
+func ParseCanonShotInfo(v []uint16) *CanonShotInfo {
+	w := words(v)
+
+	return &CanonShotInfo{
+		AutoISO:            w.signed(1),
+		BaseISO:            w.signed(2),
+		MeasuredEV:         w.signed(3),
+		TargetAperture:     w.signed(4),
+		TargetExposure:     w.signed(5),
+		ExposureComp:       w.signed(6),
+		WhiteBalance:       w.signed(7),
+		SequenceNumber:     w.signed(9),
+		CameraTemperature:  w.signed(12) - 128,
+		AFPointsInFocus:    w.at(14),
+		FocusDistanceUpper: w.at(19),
+		FocusDistanceLower: w.at(20),
+	}
+}
+
+// ParseCanonFileInfo decodes a FileInfo record of a camera of the
+// given model. The first word is the size of the record.
+func ParseCanonFileInfo(v []uint16, model string) *CanonFileInfo {
+	w := words(v)
+
+	info := &CanonFileInfo{
+		BracketMode:       w.signed(3),
+		BracketValue:      w.signed(4),
+		BracketShotNumber: w.signed(5),
+		LiveViewShooting:  w.signed(19),
+		ShutterMode:       w.signed(23),
+	}
+
+	if strings.Contains(model, "EOS-1D") {
+		info.ShutterCount = uint32(w.at(1))<<16 | uint32(w.at(2))
+	}
+
+	return info
+}
+
+// ParseCanonAFInfo decodes an AFInfo record, as found in CRW files and
+// older MakerNotes.
+func ParseCanonAFInfo(v []uint16) (*CanonAFInfo, error) {
+	w := words(v)
+
+	n := int(w.at(0))
+	if len(w) < 8+2*n+(n+15)/16 {
+		return nil, ErrMalformed
+	}
+
+	info := &CanonAFInfo{
+		ValidPoints:   w.at(1),
+		ImageWidth:    w.at(2),
+		ImageHeight:   w.at(3),
+		AFImageWidth:  w.at(4),
+		AFImageHeight: w.at(5),
+		Points:        make([]CanonAFPoint, n),
+	}
+
+	for i := range info.Points {
+		info.Points[i] = CanonAFPoint{
+			Width:   w.at(6),
+			Height:  w.at(7),
+			X:       w.signed(8 + i),
+			Y:       w.signed(8 + n + i),
+			InFocus: w.bit(8+2*n, i),
+		}
+	}
+
+	return info, nil
+}
+
+// ParseCanonAFInfo2 decodes an AFInfo2 record, as found in newer
+// MakerNotes.
+func ParseCanonAFInfo2(v []uint16) (*CanonAFInfo, error) {
+	w := words(v)
+
+	n := int(w.at(2))
+	masks := (n + 15) / 16
+
+	if len(w) < 8+4*n+masks {
+		return nil, ErrMalformed
+	}
+
+	info := &CanonAFInfo{
+		AreaMode:      w.at(1),
+		ValidPoints:   w.at(3),
+		ImageWidth:    w.at(4),
+		ImageHeight:   w.at(5),
+		AFImageWidth:  w.at(6),
+		AFImageHeight: w.at(7),
+		Points:        make([]CanonAFPoint, n),
+	}
+
+	// Not all models record the selected points.
+	selected := len(w) >= 8+4*n+2*masks
+
+	for i := range info.Points {
+		info.Points[i] = CanonAFPoint{
+			Width:    w.at(8 + i),
+			Height:   w.at(8 + n + i),
+			X:        w.signed(8 + 2*n + i),
+			Y:        w.signed(8 + 3*n + i),
+			InFocus:  w.bit(8+4*n, i),
+			Selected: selected && w.bit(8+4*n+masks, i),
+		}
+	}
+
+	return info, nil
+}
+
+// CanonSerialNumber formats a serial number recorded as a number the
+// way the camera of the given model displays it.
+func CanonSerialNumber(model string, v uint32) string {
+	switch {
+	case strings.Contains(model, "EOS D30"):
+		return fmt.Sprintf("%x-%05d", v>>16, v&0xffff)
+
+	case strings.Contains(model, "EOS-1D"):
+		return fmt.Sprintf("%06d", v)
+
+	default:
+		return fmt.Sprintf("%010d", v)
+	}
+}
+
+// Canon decodes the MakerNote as a Canon MakerNote.
+func (m *MakerNote) Canon() (*Canon, error) {
+	if m.Vendor != VendorCanon {
+		return nil, ErrUnknownVendor
+	}
+
+	c := &Canon{}
+
+	c.ModelID, _ = m.long(CanonModelIDTag)
+	c.FileNumber, _ = m.long(CanonFileNumberTag)
+	c.ImageType, _ = m.Tiff.Ascii(0, CanonImageTypeTag)
+	c.FirmwareVersion, _ = m.Tiff.Ascii(0, CanonFirmwareVersionTag)
+	c.OwnerName, _ = m.Tiff.Ascii(0, CanonOwnerNameTag)
+	c.InternalSerialNumber, _ = m.Tiff.Ascii(0, CanonInternalSerialTag)
+	c.LensModel, _ = m.Tiff.Ascii(0, CanonLensModelTag)
+
+	if serial, err := m.long(CanonSerialNumberTag); err == nil {
+		c.SerialNumber = CanonSerialNumber(m.Model, serial)
+	}
+
+	if v, err := m.words(CanonCameraSettingsTag); err == nil {
+		c.CameraSettings = ParseCanonCameraSettings(v)
+	}
+
+	if v, err := m.words(CanonShotInfoTag); err == nil {
+		c.ShotInfo = ParseCanonShotInfo(v)
+	}
+
+	if v, err := m.words(CanonFileInfoTag); err == nil {
+		c.FileInfo = ParseCanonFileInfo(v, m.Model)
+	}
+
+	if v, err := m.words(CanonAFInfo2Tag); err == nil {
+		c.AFInfo, _ = ParseCanonAFInfo2(v)
+	} else if v, err := m.words(CanonAFInfoTag); err == nil {
+		c.AFInfo, _ = ParseCanonAFInfo(v)
+	}
+
+	return c, nil
+}
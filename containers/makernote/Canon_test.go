@@ -0,0 +1,136 @@
+package makernote
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCanonCameraSettings(t *testing.T) {
+	v := make([]uint16, 35)
+	v[0] = 70
+	v[5] = 1
+	v[7] = 3
+	v[22] = 0xffff
+	v[23] = 105
+	v[24] = 24
+	v[25] = 1
+	v[34] = 0xffff
+
+	s := ParseCanonCameraSettings(v)
+
+	if s.DriveMode != CanonDriveMode(1) || s.FocusMode != CanonFocusMode(3) {
+		t.Errorf("got drive mode %d and focus mode %d", s.DriveMode, s.FocusMode)
+	}
+
+	if s.LensType != 0xffff || s.ImageStabilization != -1 {
+		t.Errorf("got lens type %d and image stabilization %d", s.LensType, s.ImageStabilization)
+	}
+
+	wide, tele := s.FocalLengths()
+	if wide != 24 || tele != 105 {
+		t.Errorf("got focal lengths %g-%g, expected 24-105", wide, tele)
+	}
+
+	// Short records from older models read as zero.
+	s = ParseCanonCameraSettings(v[:10])
+	if s.LensType != 0 || s.FocusMode != CanonFocusMode(3) {
+		t.Errorf("short record: got lens type %d and focus mode %d", s.LensType, s.FocusMode)
+	}
+}
+
+func TestParseCanonShotInfo(t *testing.T) {
+	v := make([]uint16, 34)
+	v[1] = 0
+	v[2] = 192
+	v[12] = 128 + 34
+	v[19] = 0xffff
+	v[20] = 150
+
+	s := ParseCanonShotInfo(v)
+
+	if iso := s.ISO(); iso != 200 {
+		t.Errorf("got ISO %g, expected 200", iso)
+	}
+
+	if s.CameraTemperature != 34 {
+		t.Errorf("got temperature %d, expected 34", s.CameraTemperature)
+	}
+
+	if s.FocusDistanceUpper != 0xffff || s.FocusDistanceLower != 150 {
+		t.Errorf("got focus distance %d-%d", s.FocusDistanceLower, s.FocusDistanceUpper)
+	}
+}
+
+func TestParseCanonFileInfo(t *testing.T) {
+	v := make([]uint16, 24)
+	v[1] = 0x0001
+	v[2] = 0x86a0
+
+	info := ParseCanonFileInfo(v, "Canon EOS-1D X Mark II")
+	if info.ShutterCount != 100000 {
+		t.Errorf("got shutter count %d, expected 100000", info.ShutterCount)
+	}
+
+	info = ParseCanonFileInfo(v, "Canon EOS 5D Mark IV")
+	if info.ShutterCount != 0 {
+		t.Errorf("got shutter count %d for a model not recording it", info.ShutterCount)
+	}
+}
+
+func TestParseCanonAFInfo2(t *testing.T) {
+	v := []uint16{
+		0,          // Size
+		2,          // AreaMode
+		2,          // Points
+		2,          // ValidPoints
+		6000, 4000, // ImageWidth, ImageHeight
+		6000, 4000, // AFImageWidth, AFImageHeight
+		100, 110, // Widths
+		120, 130, // Heights
+		0xfff0, 20, // X
+		30, 0xffd8, // Y
+		0x0002, // In focus
+		0x0001, // Selected
+	}
+
+	info, err := ParseCanonAFInfo2(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []CanonAFPoint{
+		{Width: 100, Height: 120, X: -16, Y: 30, Selected: true},
+		{Width: 110, Height: 130, X: 20, Y: -40, InFocus: true},
+	}
+
+	if !reflect.DeepEqual(info.Points, want) {
+		t.Errorf("got %+v, expected %+v", info.Points, want)
+	}
+
+	if got := info.InFocus(); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("got points in focus %v, expected [1]", got)
+	}
+
+	_, err = ParseCanonAFInfo2(v[:15])
+	if err != ErrMalformed {
+		t.Errorf("got %v for a truncated record, expected ErrMalformed", err)
+	}
+}
+
+func TestCanonSerialNumber(t *testing.T) {
+	cases := []struct {
+		model string
+		v     uint32
+		want  string
+	}{
+		{"Canon EOS D30", 0x00560123, "56-00291"},
+		{"Canon EOS-1D Mark III", 501234, "501234"},
+		{"Canon EOS 5D Mark IV", 12345, "0000012345"},
+	}
+
+	for _, c := range cases {
+		if got := CanonSerialNumber(c.model, c.v); got != c.want {
+			t.Errorf("%s: got %q, expected %q", c.model, got, c.want)
+		}
+	}
+}
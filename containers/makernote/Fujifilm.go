@@ -0,0 +1,149 @@
+package makernote
+
+import (
+	"fmt"
+
+	"github.com/abrander/apexif/containers/tiff"
+)
+
+// Fujifilm MakerNote tags.
+const (
+	FujifilmSerialNumberTag          tiff.Tag = 0x0010
+	FujifilmSaturationTag            tiff.Tag = 0x1003
+	FujifilmFilmModeTag              tiff.Tag = 0x1401
+	FujifilmMinFocalLengthTag        tiff.Tag = 0x1404
+	FujifilmMaxFocalLengthTag        tiff.Tag = 0x1405
+	FujifilmMaxApertureAtMinFocalTag tiff.Tag = 0x1406
+	FujifilmMaxApertureAtMaxFocalTag tiff.Tag = 0x1407
+	FujifilmImageCountTag            tiff.Tag = 0x1438
+)
+
+// Fujifilm is a decoded Fujifilm MakerNote. Fujifilm doesn't record
+// the lens model in the MakerNote, only the focal length and aperture
+// range. The EXIF LensModel tag usually holds the name.
+type Fujifilm struct {
+	SerialNumber string
+
+	// FilmSimulation is the color or monochrome film simulation.
+	FilmSimulation FujifilmFilmSimulation
+
+	// Lens is the focal length and aperture range of the lens, as
+	// minimum focal length, maximum focal length, maximum aperture
+	// at minimum focal length and maximum aperture at maximum focal
+	// length.
+	Lens [4]float64
+
+	// ShutterCount is the number of images taken by the camera. Not
+	// all models record it.
+	ShutterCount uint16
+}
+
+// FujifilmFilmSimulation is a film simulation. Color simulations use
+// the values of the FilmMode tag, monochrome simulations the values of
+// the Saturation tag.
+type FujifilmFilmSimulation uint16
+
+var _ fmt.Stringer = FujifilmFilmSimulation(0)
+
+var fujifilmFilmSimulations = map[FujifilmFilmSimulation]string{
+	0x000: "Provia",
+	0x100: "Studio Portrait",
+	0x110: "Studio Portrait Enhanced Saturation",
+	0x120: "Astia",
+	0x130: "Studio Portrait Increased Sharpness",
+	0x200: "Velvia",
+	0x300: "Studio Portrait Ex",
+	0x400: "Velvia",
+	0x500: "Pro Neg. Std",
+	0x501: "Pro Neg. Hi",
+	0x600: "Classic Chrome",
+	0x700: "Eterna",
+	0x800: "Classic Negative",
+	0x900: "Eterna Bleach Bypass",
+	0xa00: "Nostalgic Negative",
+	0xb00: "Reala Ace",
+}
+
+var fujifilmMonochromes = map[FujifilmFilmSimulation]string{
+	0x300: "Monochrome",
+	0x301: "Monochrome + R Filter",
+	0x302: "Monochrome + Ye Filter",
+	0x303: "Monochrome + G Filter",
+	0x310: "Sepia",
+	0x500: "Acros",
+	0x501: "Acros + R Filter",
+	0x502: "Acros + Ye Filter",
+	0x503: "Acros + G Filter",
+}
+
+// fujifilmMonochromeBit marks a FujifilmFilmSimulation decoded from the
+// Saturation tag.
+const fujifilmMonochromeBit FujifilmFilmSimulation = 0x8000
+
+// Monochrome returns true if the film simulation is a monochrome
+// simulation.
+func (f FujifilmFilmSimulation) Monochrome() bool {
+	return f&fujifilmMonochromeBit != 0
+}
+
+// String returns the name of the film simulation.
+func (f FujifilmFilmSimulation) String() string {
+	names := fujifilmFilmSimulations
+	if f.Monochrome() {
+		names = fujifilmMonochromes
+	}
+
+	if s, ok := names[f&^fujifilmMonochromeBit]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Unknown (0x%03x)", uint16(f&^fujifilmMonochromeBit))
+}
+
+// LensName returns a description of the lens, like "18-55mm f/2.8-4".
+func (f *Fujifilm) LensName() string {
+	return lensName(f.Lens)
+}
+
+// Fujifilm decodes the MakerNote as a Fujifilm MakerNote.
+func (m *MakerNote) Fujifilm() (*Fujifilm, error) {
+	if m.Vendor != VendorFujifilm {
+		return nil, ErrUnknownVendor
+	}
+
+	f := &Fujifilm{}
+
+	f.SerialNumber, _ = m.Tiff.Ascii(0, FujifilmSerialNumberTag)
+
+	if v, err := m.short(FujifilmFilmModeTag); err == nil {
+		f.FilmSimulation = FujifilmFilmSimulation(v)
+	}
+
+	if v, err := m.short(FujifilmSaturationTag); err == nil {
+		if _, ok := fujifilmMonochromes[FujifilmFilmSimulation(v)]; ok {
+			f.FilmSimulation = FujifilmFilmSimulation(v) | fujifilmMonochromeBit
+		}
+	}
+
+	lens := []tiff.Tag{
+		FujifilmMinFocalLengthTag,
+		FujifilmMaxFocalLengthTag,
+		FujifilmMaxApertureAtMinFocalTag,
+		FujifilmMaxApertureAtMaxFocalTag,
+	}
+
+	for i, tag := range lens {
+		if e, err := m.Entry(tag); err == nil {
+			if r, err := e.Rational(); err == nil && r.Denominator != 0 {
+				f.Lens[i] = r.Float()
+			}
+		}
+	}
+
+	// The high bit is set on some models and not part of the count.
+	if v, err := m.short(FujifilmImageCountTag); err == nil {
+		f.ShutterCount = v & 0x7fff
+	}
+
+	return f, nil
+}
@@ -0,0 +1,357 @@
+package makernote
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/abrander/apexif/containers/tiff"
+)
+
+// Nikon MakerNote tags.
+const (
+	NikonSerialNumberTag       tiff.Tag = 0x001d
+	NikonVRInfoTag             tiff.Tag = 0x001f
+	NikonActiveDLightingTag    tiff.Tag = 0x0022
+	NikonPictureControlDataTag tiff.Tag = 0x0023
+	NikonLensTypeTag           tiff.Tag = 0x0083
+	NikonLensTag               tiff.Tag = 0x0084
+	NikonShotInfoTag           tiff.Tag = 0x0091
+	NikonLensDataTag           tiff.Tag = 0x0098
+	NikonShutterCountTag       tiff.Tag = 0x00a7
+)
+
+// Nikon is a decoded Nikon MakerNote. Sections not present are nil or
+// zero.
+type Nikon struct {
+	SerialNumber string
+	ShutterCount uint32
+
+	ActiveDLighting NikonActiveDLighting
+
+	// PictureControl is the name of the Picture Control, and
+	// PictureControlBase the name of the Picture Control it's based
+	// on.
+	PictureControl     string
+	PictureControlBase string
+
+	VibrationReduction NikonVibrationReduction
+
+	// LensType holds flags describing the lens, like 0x80 for VR
+	// lenses.
+	LensType uint8
+
+	// Lens is the focal length and aperture range of the lens, as
+	// minimum focal length, maximum focal length, maximum aperture
+	// at minimum focal length and maximum aperture at maximum focal
+	// length.
+	Lens [4]float64
+
+	LensData *NikonLensData
+
+	// ShotInfo is the decrypted ShotInfo record. The layout depends
+	// on the model.
+	ShotInfo []byte
+}
+
+// NikonActiveDLighting is the Active D-Lighting setting.
+type NikonActiveDLighting uint16
+
+var _ fmt.Stringer = NikonActiveDLighting(0)
+
+// String returns a string representation of the setting.
+func (a NikonActiveDLighting) String() string {
+	names := map[NikonActiveDLighting]string{
+		0:      "Off",
+		1:      "Low",
+		3:      "Normal",
+		5:      "High",
+		7:      "Extra High",
+		8:      "Extra High 1",
+		9:      "Extra High 2",
+		10:     "Extra High 3",
+		11:     "Extra High 4",
+		0xffff: "Auto",
+	}
+
+	if s, ok := names[a]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Unknown (%d)", uint16(a))
+}
+
+// NikonVibrationReduction is the vibration reduction setting.
+type NikonVibrationReduction uint8
+
+const (
+	NikonVRUnknown NikonVibrationReduction = 0
+	NikonVROn      NikonVibrationReduction = 1
+	NikonVROff     NikonVibrationReduction = 2
+)
+
+var _ fmt.Stringer = NikonVibrationReduction(0)
+
+// String returns a string representation of the setting.
+func (v NikonVibrationReduction) String() string {
+	switch v {
+	case NikonVROn:
+		return "On"
+	case NikonVROff:
+		return "Off"
+	default:
+		return "Unknown"
+	}
+}
+
+// NikonLensData is the decrypted LensData record. The fields are only
+// decoded for the version 01xx and 02xx layouts used by DSLRs, for
+// other versions only Version and Data are set.
+type NikonLensData struct {
+	Version string
+
+	// Data is the decrypted record, including the version.
+	Data []byte
+
+	LensIDNumber uint8
+	LensFStops   uint8
+	MCUVersion   uint8
+
+	// The raw lens values below are logarithmic. Use the methods to
+	// get the values in mm and f-stops.
+	MinFocalLength        uint8
+	MaxFocalLength        uint8
+	MaxApertureAtMinFocal uint8
+	MaxApertureAtMaxFocal uint8
+}
+
+// FocalLengths returns the focal length range of the lens in mm.
+func (l *NikonLensData) FocalLengths() (float64, float64) {
+	return nikonFocalLength(l.MinFocalLength), nikonFocalLength(l.MaxFocalLength)
+}
+
+// MaxApertures returns the maximum aperture of the lens at the minimum
+// and maximum focal length.
+func (l *NikonLensData) MaxApertures() (float64, float64) {
+	return nikonAperture(l.MaxApertureAtMinFocal), nikonAperture(l.MaxApertureAtMaxFocal)
+}
+
+func nikonFocalLength(v uint8) float64 {
+	return 5 * math.Pow(2, float64(v)/24)
+}
+
+func nikonAperture(v uint8) float64 {
+	return math.Pow(2, float64(v)/24)
+}
+
+// LensID returns the lens identifier as eight hex bytes, like
+// "4A 48 24 24 24 0C 4D 02", as used by lens databases. An empty
+// string is returned if no LensData was decoded.
+func (n *Nikon) LensID() string {
+	l := n.LensData
+	if l == nil || l.LensIDNumber == 0 && l.MinFocalLength == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%02X %02X %02X %02X %02X %02X %02X %02X",
+		l.LensIDNumber, l.LensFStops, l.MinFocalLength, l.MaxFocalLength,
+		l.MaxApertureAtMinFocal, l.MaxApertureAtMaxFocal, l.MCUVersion, n.LensType)
+}
+
+// LensName returns a description of the lens, like "24-70mm f/2.8" or
+// "18-55mm f/3.5-5.6", from the Lens record.
+func (n *Nikon) LensName() string {
+	return lensName(n.Lens)
+}
+
+// Nikon decodes the MakerNote as a Nikon MakerNote. LensData and
+// ShotInfo are decrypted using the serial number and shutter count.
+func (m *MakerNote) Nikon() (*Nikon, error) {
+	if m.Vendor != VendorNikon {
+		return nil, ErrUnknownVendor
+	}
+
+	n := &Nikon{}
+
+	n.SerialNumber, _ = m.Tiff.Ascii(0, NikonSerialNumberTag)
+	n.ShutterCount, _ = m.long(NikonShutterCountTag)
+
+	if e, err := m.Entry(NikonActiveDLightingTag); err == nil {
+		v, _ := e.Short()
+		n.ActiveDLighting = NikonActiveDLighting(v)
+	}
+
+	if data, err := m.bytes(NikonPictureControlDataTag); err == nil {
+		n.PictureControl, n.PictureControlBase = nikonPictureControl(data)
+	}
+
+	if data, err := m.bytes(NikonVRInfoTag); err == nil && len(data) > 4 {
+		n.VibrationReduction = NikonVibrationReduction(data[4])
+	}
+
+	if e, err := m.Entry(NikonLensTypeTag); err == nil {
+		n.LensType, _ = e.Byte()
+	}
+
+	if e, err := m.Entry(NikonLensTag); err == nil {
+		r, err := e.RationalSlice()
+		if err == nil && len(r) == 4 {
+			for i := range r {
+				if r[i].Denominator != 0 {
+					n.Lens[i] = r[i].Float()
+				}
+			}
+		}
+	}
+
+	serial := nikonSerialKey(n.SerialNumber, m.Model)
+
+	if data, err := m.bytes(NikonLensDataTag); err == nil && len(data) > 4 {
+		n.LensData = parseNikonLensData(data, serial, n.ShutterCount)
+	}
+
+	if data, err := m.bytes(NikonShotInfoTag); err == nil && len(data) > 4 {
+		if encrypted(data) {
+			nikonDecrypt(data[4:], serial, n.ShutterCount)
+		}
+
+		n.ShotInfo = data
+	}
+
+	return n, nil
+}
+
+// nikonPictureControl returns the name and base name from a
+// PictureControlData record. The names follow the version, with an
+// extra 4 bytes in version 3 and later.
+func nikonPictureControl(data []byte) (string, string) {
+	start := 4
+	if strings.HasPrefix(string(data), "03") {
+		start = 8
+	}
+
+	if len(data) < start+40 {
+		return "", ""
+	}
+
+	return cString(data[start : start+20]), cString(data[start+20 : start+40])
+}
+
+// encrypted returns true if a record with a four character version is
+// encrypted. Only the oldest versions are stored in clear text.
+func encrypted(data []byte) bool {
+	return !strings.HasPrefix(string(data), "01")
+}
+
+// nikonSerialKey returns the serial number used as a key. Models with
+// a non-numeric serial number use a fixed key.
+func nikonSerialKey(serial string, model string) uint32 {
+	v, err := strconv.ParseUint(serial, 10, 32)
+	if err == nil {
+		return uint32(v)
+	}
+
+	if strings.HasSuffix(model, "D50") {
+		return 0x22
+	}
+
+	return 0x60
+}
+
+// parseNikonLensData decrypts and decodes a LensData record.
+func parseNikonLensData(data []byte, serial uint32, count uint32) *NikonLensData {
+	l := &NikonLensData{
+		Version: string(data[:4]),
+		Data:    data,
+	}
+
+	if encrypted(data) {
+		nikonDecrypt(data[4:], serial, count)
+	}
+
+	// The offset of the lens ID number. The other fields follow.
+	var offset int
+
+	switch {
+	case l.Version == "0100":
+		offset = 0x06
+	case l.Version == "0204":
+		offset = 0x0c
+	case strings.HasPrefix(l.Version, "01"), strings.HasPrefix(l.Version, "02"):
+		offset = 0x0b
+	default:
+		return l
+	}
+
+	if len(data) < offset+7 {
+		return l
+	}
+
+	l.LensIDNumber = data[offset]
+	l.LensFStops = data[offset+1]
+	l.MinFocalLength = data[offset+2]
+	l.MaxFocalLength = data[offset+3]
+	l.MaxApertureAtMinFocal = data[offset+4]
+	l.MaxApertureAtMaxFocal = data[offset+5]
+	l.MCUVersion = data[offset+6]
+
+	return l
+}
+
+// nikonDecrypt decrypts data in place. The key stream is derived from
+// the serial number and the shutter count.
+func nikonDecrypt(data []byte, serial uint32, count uint32) {
+	key := byte(count) ^ byte(count>>8) ^ byte(count>>16) ^ byte(count>>24)
+
+	ci := nikonXlat[0][serial&0xff]
+	cj := nikonXlat[1][key]
+	ck := byte(0x60)
+
+	for i := range data {
+		cj += ci * ck
+		ck++
+
+		data[i] ^= cj
+	}
+}
+
+// nikonXlat are the substitution tables used to derive the key stream.
+var nikonXlat = [2][256]byte{
+	{
+		0xc1, 0xbf, 0x6d, 0x0d, 0x59, 0xc5, 0x13, 0x9d, 0x83, 0x61, 0x6b, 0x4f, 0xc7, 0x7f, 0x3d, 0x3d,
+		0x53, 0x59, 0xe3, 0xc7, 0xe9, 0x2f, 0x95, 0xa7, 0x95, 0x1f, 0xdf, 0x7f, 0x2b, 0x29, 0xc7, 0x0d,
+		0xdf, 0x07, 0xef, 0x71, 0x89, 0x3d, 0x13, 0x3d, 0x3b, 0x13, 0xfb, 0x0d, 0x89, 0xc1, 0x65, 0x1f,
+		0xb3, 0x0d, 0x6b, 0x29, 0xe3, 0xfb, 0xef, 0xa3, 0x6b, 0x47, 0x7f, 0x95, 0x35, 0xa7, 0x47, 0x4f,
+		0xc7, 0xf1, 0x59, 0x95, 0x35, 0x11, 0x29, 0x61, 0xf1, 0x3d, 0xb3, 0x2b, 0x0d, 0x43, 0x89, 0xc1,
+		0x9d, 0x9d, 0x89, 0x65, 0xf1, 0xe9, 0xdf, 0xbf, 0x3d, 0x7f, 0x53, 0x97, 0xe5, 0xe9, 0x95, 0x17,
+		0x1d, 0x3d, 0x8b, 0xfb, 0xc7, 0xe3, 0x67, 0xa7, 0x07, 0xf1, 0x71, 0xa7, 0x53, 0xb5, 0x29, 0x89,
+		0xe5, 0x2b, 0xa7, 0x17, 0x29, 0xe9, 0x4f, 0xc5, 0x65, 0x6d, 0x6b, 0xef, 0x0d, 0x89, 0x49, 0x2f,
+		0xb3, 0x43, 0x53, 0x65, 0x1d, 0x49, 0xa3, 0x13, 0x89, 0x59, 0xef, 0x6b, 0xef, 0x65, 0x1d, 0x0b,
+		0x59, 0x13, 0xe3, 0x4f, 0x9d, 0xb3, 0x29, 0x43, 0x2b, 0x07, 0x1d, 0x95, 0x59, 0x59, 0x47, 0xfb,
+		0xe5, 0xe9, 0x61, 0x47, 0x2f, 0x35, 0x7f, 0x17, 0x7f, 0xef, 0x7f, 0x95, 0x95, 0x71, 0xd3, 0xa3,
+		0x0b, 0x71, 0xa3, 0xad, 0x0b, 0x3b, 0xb5, 0xfb, 0xa3, 0xbf, 0x4f, 0x83, 0x1d, 0xad, 0xe9, 0x2f,
+		0x71, 0x65, 0xa3, 0xe5, 0x07, 0x35, 0x3d, 0x0d, 0xb5, 0xe9, 0xe5, 0x47, 0x3b, 0x9d, 0xef, 0x35,
+		0xa3, 0xbf, 0xb3, 0xdf, 0x53, 0xd3, 0x97, 0x53, 0x49, 0x71, 0x07, 0x35, 0x61, 0x71, 0x2f, 0x43,
+		0x2f, 0x11, 0xdf, 0x17, 0x97, 0xfb, 0x95, 0x3b, 0x7f, 0x6b, 0xd3, 0x25, 0xbf, 0xad, 0xc7, 0xc5,
+		0xc5, 0xb5, 0x8b, 0xef, 0x2f, 0xd3, 0x07, 0x6b, 0x25, 0x49, 0x95, 0x25, 0x49, 0x6d, 0x71, 0xc7,
+	},
+	{
+		0xa7, 0xbc, 0xc9, 0xad, 0x91, 0xdf, 0x85, 0xe5, 0xd4, 0x78, 0xd5, 0x17, 0x46, 0x7c, 0x29, 0x4c,
+		0x4d, 0x03, 0xe9, 0x25, 0x68, 0x11, 0x86, 0xb3, 0xbd, 0xf7, 0x6f, 0x61, 0x22, 0xa2, 0x26, 0x34,
+		0x2a, 0xbe, 0x1e, 0x46, 0x14, 0x68, 0x9d, 0x44, 0x18, 0xc2, 0x40, 0xf4, 0x7e, 0x5f, 0x1b, 0xad,
+		0x0b, 0x94, 0xb6, 0x67, 0xb4, 0x0b, 0xe1, 0xea, 0x95, 0x9c, 0x66, 0xdc, 0xe7, 0x5d, 0x6c, 0x05,
+		0xda, 0xd5, 0xdf, 0x7a, 0xef, 0xf6, 0xdb, 0x1f, 0x82, 0x4c, 0xc0, 0x68, 0x47, 0xa1, 0xbd, 0xee,
+		0x39, 0x50, 0x56, 0x4a, 0xdd, 0xdf, 0xa5, 0xf8, 0xc6, 0xda, 0xca, 0x90, 0xca, 0x01, 0x42, 0x9d,
+		0x8b, 0x0c, 0x73, 0x43, 0x75, 0x05, 0x94, 0xde, 0x24, 0xb3, 0x80, 0x34, 0xe5, 0x2c, 0xdc, 0x9b,
+		0x3f, 0xca, 0x33, 0x45, 0xd0, 0xdb, 0x5f, 0xf5, 0x52, 0xc3, 0x21, 0xda, 0xe2, 0x22, 0x72, 0x6b,
+		0x3e, 0xd0, 0x5b, 0xa8, 0x87, 0x8c, 0x06, 0x5d, 0x0f, 0xdd, 0x09, 0x19, 0x93, 0xd0, 0xb9, 0xfc,
+		0x8b, 0x0f, 0x84, 0x60, 0x33, 0x1c, 0x9b, 0x45, 0xf1, 0xf0, 0xa3, 0x94, 0x3a, 0x12, 0x77, 0x33,
+		0x4d, 0x44, 0x78, 0x28, 0x3c, 0x9e, 0xfd, 0x65, 0x57, 0x16, 0x94, 0x6b, 0xfb, 0x59, 0xd0, 0xc8,
+		0x22, 0x36, 0xdb, 0xd2, 0x63, 0x98, 0x43, 0xa1, 0x04, 0x87, 0x86, 0xf7, 0xa6, 0x26, 0xbb, 0xd6,
+		0x59, 0x4d, 0xbf, 0x6a, 0x2e, 0xaa, 0x2b, 0xef, 0xe6, 0x78, 0xb6, 0x4e, 0xe0, 0x2f, 0xdc, 0x7c,
+		0xbe, 0x57, 0x19, 0x32, 0x7e, 0x2a, 0xd0, 0xb8, 0xba, 0x29, 0x00, 0x3c, 0x52, 0x7d, 0xa8, 0x49,
+		0x3b, 0x2d, 0xeb, 0x25, 0x49, 0xfa, 0xa3, 0xaa, 0x39, 0xa7, 0xc5, 0xa7, 0x50, 0x11, 0x36, 0xfb,
+		0xc6, 0x67, 0x4a, 0xf5, 0xa5, 0x12, 0x65, 0x7e, 0xb0, 0xdf, 0xaf, 0x4e, 0xb3, 0x61, 0x7f, 0x2f,
+	},
+}
@@ -0,0 +1,82 @@
+package makernote
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseNikonLensData(t *testing.T) {
+	// A version 0204 record of a 14mm f/2.8 lens, from a camera with
+	// serial number 3012345 and shutter count 12345. Everything after
+	// the version is encrypted.
+	data := []byte("0204" +
+		"\xc8\xa1\x43\xee\x62\x9f\x65\xf4" +
+		"\x56\x45\x63\xee\xb2\xa7\x44" +
+		"\xb1\xa2\xda\x5f\x23")
+
+	plain := []byte("0204" +
+		"\x10\x20\x30\x40\x50\x60\x70\x80" +
+		"\x4a\x48\x24\x24\x24\x0c\x4d" +
+		"\x01\x02\x03\x04\x05")
+
+	l := parseNikonLensData(data, nikonSerialKey("3012345", "NIKON D3"), 12345)
+
+	if !bytes.Equal(l.Data, plain) {
+		t.Errorf("got % x, expected % x", l.Data, plain)
+	}
+
+	n := &Nikon{LensData: l, LensType: 0x02}
+	if id := n.LensID(); id != "4A 48 24 24 24 0C 4D 02" {
+		t.Errorf("got lens ID %q", id)
+	}
+
+	wide, tele := l.FocalLengths()
+	if wide < 14 || wide > 14.2 || tele != wide {
+		t.Errorf("got focal lengths %g-%g, expected 14.1", wide, tele)
+	}
+}
+
+func TestParseNikonLensDataPlain(t *testing.T) {
+	// Version 0100 records are not encrypted.
+	data := []byte("0100\x00\x00\x4a\x48\x24\x24\x24\x0c\x4d")
+
+	l := parseNikonLensData(data, 0, 0)
+
+	if l.LensIDNumber != 0x4a || l.MCUVersion != 0x4d {
+		t.Errorf("got lens ID number %#x and MCU version %#x", l.LensIDNumber, l.MCUVersion)
+	}
+}
+
+func TestNikonDecrypt(t *testing.T) {
+	plain := []byte("The quick brown fox")
+
+	data := append([]byte{}, plain...)
+
+	nikonDecrypt(data, 3012345, 12345)
+	if bytes.Equal(data, plain) {
+		t.Fatalf("data was not changed")
+	}
+
+	nikonDecrypt(data, 3012345, 12345)
+	if !bytes.Equal(data, plain) {
+		t.Errorf("got %q after decrypting twice, expected %q", data, plain)
+	}
+}
+
+func TestNikonSerialKey(t *testing.T) {
+	cases := []struct {
+		serial string
+		model  string
+		want   uint32
+	}{
+		{"3012345", "NIKON D3", 3012345},
+		{"No= 3012345", "NIKON D50", 0x22},
+		{"", "NIKON D70", 0x60},
+	}
+
+	for _, c := range cases {
+		if got := nikonSerialKey(c.serial, c.model); got != c.want {
+			t.Errorf("%q %q: got %#x, expected %#x", c.serial, c.model, got, c.want)
+		}
+	}
+}
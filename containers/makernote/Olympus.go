@@ -0,0 +1,129 @@
+package makernote
+
+import (
+	"fmt"
+
+	"github.com/abrander/apexif/containers/tiff"
+)
+
+// Olympus MakerNote tags. The Equipment and CameraSettings tags point
+// to IFDs of their own.
+const (
+	OlympusSerialNumberTag   tiff.Tag = 0x0404
+	OlympusEquipmentTag      tiff.Tag = 0x2010
+	OlympusCameraSettingsTag tiff.Tag = 0x2020
+)
+
+// Olympus Equipment IFD tags.
+const (
+	OlympusBodySerialNumberTag tiff.Tag = 0x0101
+	OlympusLensTypeTag         tiff.Tag = 0x0201
+	OlympusLensSerialNumberTag tiff.Tag = 0x0202
+	OlympusLensModelTag        tiff.Tag = 0x0203
+)
+
+// Olympus CameraSettings IFD tags.
+const (
+	OlympusPictureModeTag tiff.Tag = 0x0520
+)
+
+// Olympus is a decoded Olympus or OM System MakerNote. Olympus doesn't
+// record a shutter count.
+type Olympus struct {
+	SerialNumber string
+
+	LensModel        string
+	LensSerialNumber string
+
+	// LensType identifies the lens. The first byte is the make, the
+	// third the model and the fourth the sub model.
+	LensType []byte
+
+	PictureMode OlympusPictureMode
+}
+
+// OlympusPictureMode is the picture mode.
+type OlympusPictureMode uint16
+
+var _ fmt.Stringer = OlympusPictureMode(0)
+
+// String returns a string representation of the picture mode.
+func (p OlympusPictureMode) String() string {
+	names := map[OlympusPictureMode]string{
+		1:   "Vivid",
+		2:   "Natural",
+		3:   "Muted",
+		4:   "Portrait",
+		5:   "i-Enhance",
+		6:   "e-Portrait",
+		7:   "Color Creator",
+		8:   "Underwater",
+		9:   "Color Profile 1",
+		10:  "Color Profile 2",
+		11:  "Color Profile 3",
+		12:  "Monochrome Profile 1",
+		13:  "Monochrome Profile 2",
+		14:  "Monochrome Profile 3",
+		17:  "Art Mode",
+		18:  "Monochrome Profile 4",
+		256: "Monotone",
+		512: "Sepia",
+	}
+
+	if s, ok := names[p]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Unknown (%d)", uint16(p))
+}
+
+// Olympus decodes the MakerNote as an Olympus MakerNote.
+func (m *MakerNote) Olympus() (*Olympus, error) {
+	if m.Vendor != VendorOlympus {
+		return nil, ErrUnknownVendor
+	}
+
+	o := &Olympus{}
+
+	// Older models store the serial number in the MakerNote IFD.
+	o.SerialNumber, _ = m.Tiff.Ascii(0, OlympusSerialNumberTag)
+
+	if equipment, err := m.ifd(OlympusEquipmentTag); err == nil {
+		if serial, err := equipment.Ascii(0, OlympusBodySerialNumberTag); err == nil {
+			o.SerialNumber = serial
+		}
+
+		o.LensModel, _ = equipment.Ascii(0, OlympusLensModelTag)
+		o.LensSerialNumber, _ = equipment.Ascii(0, OlympusLensSerialNumberTag)
+
+		if e, err := equipment.Entry(0, OlympusLensTypeTag); err == nil {
+			o.LensType, _ = e.Bytes()
+		}
+	}
+
+	if settings, err := m.ifd(OlympusCameraSettingsTag); err == nil {
+		// The first value is the mode, the rest are model specific.
+		if e, err := settings.Entry(0, OlympusPictureModeTag); err == nil {
+			if v, err := e.ShortSlice(); err == nil && len(v) > 0 {
+				o.PictureMode = OlympusPictureMode(v[0])
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// ifd parses the IFD pointed to by an entry in the MakerNote IFD. The
+// entry is either of the IFD or Long type holding the offset, or of
+// the Undefined type holding the IFD itself. Offsets in the IFD use
+// the same base as the MakerNote IFD.
+func (m *MakerNote) ifd(tag tiff.Tag) (*tiff.Tiff, error) {
+	e, err := m.Entry(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	r := m.Tiff.Reader()
+
+	return tiff.ParseIFD(r, r.Size(), m.Tiff.ByteOrder(), int64(e.Offset()))
+}
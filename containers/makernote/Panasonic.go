@@ -0,0 +1,80 @@
+package makernote
+
+import (
+	"fmt"
+
+	"github.com/abrander/apexif/containers/tiff"
+)
+
+// Panasonic MakerNote tags.
+const (
+	PanasonicSerialNumberTag     tiff.Tag = 0x0025
+	PanasonicLensTypeTag         tiff.Tag = 0x0051
+	PanasonicLensSerialNumberTag tiff.Tag = 0x0052
+	PanasonicPhotoStyleTag       tiff.Tag = 0x0089
+)
+
+// Panasonic is a decoded Panasonic MakerNote. Panasonic doesn't record
+// a shutter count.
+type Panasonic struct {
+	SerialNumber string
+
+	LensModel        string
+	LensSerialNumber string
+
+	PhotoStyle PanasonicPhotoStyle
+}
+
+// PanasonicPhotoStyle is the Photo Style.
+type PanasonicPhotoStyle uint16
+
+var _ fmt.Stringer = PanasonicPhotoStyle(0)
+
+// String returns a string representation of the Photo Style.
+func (p PanasonicPhotoStyle) String() string {
+	names := map[PanasonicPhotoStyle]string{
+		0:  "Auto",
+		1:  "Standard or Custom",
+		2:  "Vivid",
+		3:  "Natural",
+		4:  "Monochrome",
+		5:  "Scenery",
+		6:  "Portrait",
+		8:  "Cinelike D",
+		9:  "Cinelike V",
+		11: "L. Monochrome",
+		12: "Like709",
+		15: "L. Monochrome D",
+		17: "V-Log",
+		18: "Cinelike D2",
+	}
+
+	if s, ok := names[p]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Unknown (%d)", uint16(p))
+}
+
+// Panasonic decodes the MakerNote as a Panasonic MakerNote.
+func (m *MakerNote) Panasonic() (*Panasonic, error) {
+	if m.Vendor != VendorPanasonic {
+		return nil, ErrUnknownVendor
+	}
+
+	p := &Panasonic{}
+
+	// The serial number is stored as Undefined.
+	if data, err := m.bytes(PanasonicSerialNumberTag); err == nil {
+		p.SerialNumber = cString(data)
+	}
+
+	p.LensModel, _ = m.Tiff.Ascii(0, PanasonicLensTypeTag)
+	p.LensSerialNumber, _ = m.Tiff.Ascii(0, PanasonicLensSerialNumberTag)
+
+	if v, err := m.short(PanasonicPhotoStyleTag); err == nil {
+		p.PhotoStyle = PanasonicPhotoStyle(v)
+	}
+
+	return p, nil
+}
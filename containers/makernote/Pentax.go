@@ -0,0 +1,98 @@
+package makernote
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/abrander/apexif/containers/tiff"
+)
+
+// Pentax MakerNote tags.
+const (
+	PentaxDateTag         tiff.Tag = 0x0006
+	PentaxTimeTag         tiff.Tag = 0x0007
+	PentaxLensTypeTag     tiff.Tag = 0x003f
+	PentaxImageToneTag    tiff.Tag = 0x004f
+	PentaxShutterCountTag tiff.Tag = 0x005d
+	PentaxSerialNumberTag tiff.Tag = 0x0229
+)
+
+// Pentax is a decoded Pentax MakerNote. Pentax doesn't record the lens
+// model, only an identifier.
+type Pentax struct {
+	SerialNumber string
+
+	// LensType identifies the lens as a series and a model number.
+	LensType [2]uint8
+
+	ImageTone PentaxImageTone
+
+	ShutterCount uint32
+}
+
+// PentaxImageTone is the Custom Image setting.
+type PentaxImageTone uint16
+
+var _ fmt.Stringer = PentaxImageTone(0)
+
+// String returns a string representation of the Custom Image.
+func (t PentaxImageTone) String() string {
+	names := map[PentaxImageTone]string{
+		0:   "Natural",
+		1:   "Bright",
+		2:   "Portrait",
+		3:   "Landscape",
+		4:   "Vibrant",
+		5:   "Monochrome",
+		6:   "Muted",
+		7:   "Reversal Film",
+		8:   "Bleach Bypass",
+		9:   "Radiant",
+		10:  "Cross Processing",
+		11:  "Flat",
+		256: "Standard",
+	}
+
+	if s, ok := names[t]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Unknown (%d)", uint16(t))
+}
+
+// Pentax decodes the MakerNote as a Pentax MakerNote.
+func (m *MakerNote) Pentax() (*Pentax, error) {
+	if m.Vendor != VendorPentax {
+		return nil, ErrUnknownVendor
+	}
+
+	p := &Pentax{}
+
+	p.SerialNumber, _ = m.Tiff.Ascii(0, PentaxSerialNumberTag)
+
+	if data, err := m.bytes(PentaxLensTypeTag); err == nil && len(data) >= 2 {
+		p.LensType = [2]uint8{data[0], data[1]}
+	}
+
+	if v, err := m.short(PentaxImageToneTag); err == nil {
+		p.ImageTone = PentaxImageTone(v)
+	}
+
+	count, errCount := m.bytes(PentaxShutterCountTag)
+	date, errDate := m.bytes(PentaxDateTag)
+	tm, errTime := m.bytes(PentaxTimeTag)
+
+	if errCount == nil && errDate == nil && errTime == nil && len(count) >= 4 && len(date) >= 4 && len(tm) >= 3 {
+		p.ShutterCount = pentaxShutterCount(count, date, tm)
+	}
+
+	return p, nil
+}
+
+// pentaxShutterCount decrypts the shutter count. It's XOR'ed with the
+// date and the inverted time of the image, all stored big endian.
+func pentaxShutterCount(count []byte, date []byte, tm []byte) uint32 {
+	t := uint32(tm[0])<<24 | uint32(tm[1])<<16 | uint32(tm[2])<<8
+
+	return binary.BigEndian.Uint32(count) ^ binary.BigEndian.Uint32(date) ^ ^t
+}
@@ -0,0 +1,15 @@
+package makernote
+
+import "testing"
+
+func TestPentaxShutterCount(t *testing.T) {
+	// 12345 taken 2020-06-15 13:45:30. The count is XOR'ed with the
+	// date 07e4 06 0f and the inverted time 0d 2d 1e 00.
+	count := []byte{0xf5, 0x36, 0xd7, 0xc9}
+	date := []byte{0x07, 0xe4, 0x06, 0x0f}
+	tm := []byte{0x0d, 0x2d, 0x1e}
+
+	if got := pentaxShutterCount(count, date, tm); got != 12345 {
+		t.Errorf("got %d, expected 12345", got)
+	}
+}
@@ -0,0 +1,139 @@
+package makernote
+
+import (
+	"encoding/binary"
+	"regexp"
+
+	"github.com/abrander/apexif/containers/tiff"
+)
+
+// Sony MakerNote tags.
+const (
+	SonyTag9050Tag       tiff.Tag = 0x9050
+	SonyCreativeStyleTag tiff.Tag = 0xb020
+	SonyLensTypeTag      tiff.Tag = 0xb027
+	SonyLensSpecTag      tiff.Tag = 0xb02a
+)
+
+// Sony is a decoded Sony MakerNote. Sony records the serial number in
+// the EXIF BodySerialNumber tag rather than the MakerNote.
+type Sony struct {
+	// CreativeStyle is the name of the Creative Style, like "Standard"
+	// or "Vivid".
+	CreativeStyle string
+
+	// LensType identifies A-mount lenses, and is 0xffff for E-mount
+	// lenses.
+	LensType uint32
+
+	// Lens is the focal length and aperture range of the lens, as
+	// minimum focal length, maximum focal length, maximum aperture
+	// at minimum focal length and maximum aperture at maximum focal
+	// length.
+	Lens [4]float64
+
+	// ShutterCount is the number of images taken by the camera. It's
+	// only decoded for models with a known layout of the 0x9050
+	// record.
+	ShutterCount uint32
+}
+
+// LensName returns a description of the lens, like "18-55mm f/3.5-5.6".
+func (s *Sony) LensName() string {
+	return lensName(s.Lens)
+}
+
+// Sony decodes the MakerNote as a Sony MakerNote.
+func (m *MakerNote) Sony() (*Sony, error) {
+	if m.Vendor != VendorSony {
+		return nil, ErrUnknownVendor
+	}
+
+	s := &Sony{}
+
+	s.CreativeStyle, _ = m.Tiff.Ascii(0, SonyCreativeStyleTag)
+	s.LensType, _ = m.long(SonyLensTypeTag)
+
+	if data, err := m.bytes(SonyLensSpecTag); err == nil && len(data) == 8 {
+		s.Lens = sonyLensSpec(data)
+	}
+
+	if data, err := m.bytes(SonyTag9050Tag); err == nil {
+		offset := sonyShutterCountOffset(m.Model)
+		if offset > 0 && len(data) >= offset+4 {
+			sonyDecipher(data[offset : offset+4])
+
+			// The high byte isn't part of the count.
+			s.ShutterCount = binary.LittleEndian.Uint32(data[offset:]) & 0x00ffffff
+		}
+	}
+
+	return s, nil
+}
+
+// sonyLensSpec decodes the BCD encoded LensSpec record. The first and
+// last byte are flags describing the lens.
+func sonyLensSpec(data []byte) [4]float64 {
+	bcd := func(b ...byte) float64 {
+		var v float64
+		for _, x := range b {
+			v = v*100 + float64(x>>4)*10 + float64(x&0x0f)
+		}
+
+		return v
+	}
+
+	return [4]float64{
+		bcd(data[1], data[2]),
+		bcd(data[3], data[4]),
+		bcd(data[5]) / 10,
+		bcd(data[6]) / 10,
+	}
+}
+
+// sonyTag9050a and sonyTag9050b match the models known to use the
+// older and newer layout of the 0x9050 record. Newer models use other
+// layouts.
+var (
+	sonyTag9050a = regexp.MustCompile(`^(SLT-A\d+V?|NEX-\w+|ILCA-(68|77M2)|ILCE-(3000|3500|5000|5100|6000|7|7R|7S|QX1))$`)
+	sonyTag9050b = regexp.MustCompile(`^(ILCA-99M2|ILCE-(6100|6300|6400|6500|6600|7C|7M3|7RM2|7RM3A?|7RM4A?|7SM2|9|9M2)|ZV-E10)$`)
+)
+
+// sonyShutterCountOffset returns the offset of the shutter count in the
+// 0x9050 record, or 0 if the layout is unknown for the model.
+func sonyShutterCountOffset(model string) int {
+	switch {
+	case sonyTag9050a.MatchString(model):
+		return 0x32
+
+	case sonyTag9050b.MatchString(model):
+		return 0x3a
+
+	default:
+		return 0
+	}
+}
+
+// sonyDecipherTable maps enciphered bytes to plain bytes. Bytes below
+// 249 are enciphered as b³ mod 249, the rest are stored as is.
+var sonyDecipherTable = func() [256]byte {
+	var table [256]byte
+
+	for b := 0; b < 256; b++ {
+		c := b
+		if b < 249 {
+			c = b * b * b % 249
+		}
+
+		table[c] = byte(b)
+	}
+
+	return table
+}()
+
+// sonyDecipher deciphers data in place.
+func sonyDecipher(data []byte) {
+	for i, c := range data {
+		data[i] = sonyDecipherTable[c]
+	}
+}
@@ -0,0 +1,59 @@
+package makernote
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSonyDecipher(t *testing.T) {
+	// Bytes below 249 are enciphered as b³ mod 249, so 2, 3 and 10
+	// are stored as 8, 27 and 4. 250 is stored as is.
+	data := []byte{8, 27, 4, 250}
+
+	sonyDecipher(data)
+
+	if want := []byte{2, 3, 10, 250}; !bytes.Equal(data, want) {
+		t.Errorf("got %v, expected %v", data, want)
+	}
+
+	seen := make(map[byte]bool)
+	for _, b := range sonyDecipherTable {
+		seen[b] = true
+	}
+
+	if len(seen) != 256 {
+		t.Errorf("table maps to %d distinct bytes, expected 256", len(seen))
+	}
+}
+
+func TestSonyShutterCountOffset(t *testing.T) {
+	cases := []struct {
+		model string
+		want  int
+	}{
+		{"SLT-A99V", 0x32},
+		{"NEX-5N", 0x32},
+		{"ILCE-7R", 0x32},
+		{"ILCE-7RM3A", 0x3a},
+		{"ILCE-9", 0x3a},
+		{"ZV-E10", 0x3a},
+		{"ILCE-7M4", 0},
+		{"ILCE-1", 0},
+		{"DSC-RX100M7", 0},
+	}
+
+	for _, c := range cases {
+		if got := sonyShutterCountOffset(c.model); got != c.want {
+			t.Errorf("%s: got %#x, expected %#x", c.model, got, c.want)
+		}
+	}
+}
+
+func TestSonyLensSpec(t *testing.T) {
+	// A 24-70mm f/2.8 lens, BCD encoded between two flag bytes.
+	lens := sonyLensSpec([]byte{0x00, 0x00, 0x24, 0x00, 0x70, 0x28, 0x28, 0x00})
+
+	if want := [4]float64{24, 70, 2.8, 2.8}; lens != want {
+		t.Errorf("got %v, expected %v", lens, want)
+	}
+}
@@ -0,0 +1,306 @@
+package makernote
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/abrander/apexif/containers/exif"
+	"github.com/abrander/apexif/containers/tiff"
+)
+
+var (
+	// ErrNoMakerNote is returned if the EXIF data carries no
+	// MakerNote.
+	ErrNoMakerNote = errors.New("no MakerNote found")
+
+	// ErrUnknownVendor is returned if no decoder recognizes the
+	// MakerNote.
+	ErrUnknownVendor = errors.New("unknown MakerNote vendor")
+
+	// ErrMalformed is returned if the MakerNote doesn't match the
+	// layout of the vendor.
+	ErrMalformed = errors.New("malformed MakerNote")
+)
+
+// Vendor identifies the maker of a MakerNote.
+type Vendor string
+
+const (
+	VendorApple     Vendor = "Apple"
+	VendorCanon     Vendor = "Canon"
+	VendorFujifilm  Vendor = "Fujifilm"
+	VendorNikon     Vendor = "Nikon"
+	VendorOlympus   Vendor = "Olympus"
+	VendorPanasonic Vendor = "Panasonic"
+	VendorPentax    Vendor = "Pentax"
+	VendorSony      Vendor = "Sony"
+)
+
+// Base tells what the offsets in a MakerNote IFD are relative to.
+type Base uint8
+
+const (
+	// BaseTIFF means offsets are relative to the TIFF header of the
+	// EXIF data, like offsets in the rest of the EXIF data.
+	BaseTIFF Base = iota
+
+	// BaseMakerNote means offsets are relative to the start of the
+	// MakerNote.
+	BaseMakerNote
+
+	// BaseEmbedded means the MakerNote holds a TIFF header of its
+	// own at IFDOffset, and offsets are relative to that. The byte
+	// order is read from the header.
+	BaseEmbedded
+)
+
+// Layout describes how the IFD of a MakerNote is stored.
+type Layout struct {
+	Vendor Vendor
+
+	// IFDOffset is the offset of the IFD, or the embedded TIFF
+	// header, relative to the start of the MakerNote.
+	IFDOffset int64
+
+	Base Base
+
+	// Order is the byte order of the IFD. If nil, the byte order of
+	// the EXIF data is used.
+	Order binary.ByteOrder
+}
+
+// Decoder recognizes MakerNotes of a vendor.
+type Decoder interface {
+	// Detect returns the layout of the MakerNote if the decoder
+	// recognizes it. mk is the normalized make of the camera, data
+	// the raw MakerNote and order the byte order of the EXIF data.
+	Detect(mk string, data []byte, order binary.ByteOrder) (Layout, bool)
+}
+
+// DecoderFunc is a function implementing Decoder.
+type DecoderFunc func(mk string, data []byte, order binary.ByteOrder) (Layout, bool)
+
+// Detect calls f.
+func (f DecoderFunc) Detect(mk string, data []byte, order binary.ByteOrder) (Layout, bool) {
+	return f(mk, data, order)
+}
+
+var (
+	decodersLock sync.RWMutex
+	decoders     = builtin
+)
+
+// Register registers a decoder. Decoders registered later take
+// precedence, so built-in decoders can be replaced.
+func Register(d Decoder) {
+	decodersLock.Lock()
+	defer decodersLock.Unlock()
+
+	decoders = append([]Decoder{d}, decoders...)
+}
+
+// detect returns the layout from the first decoder recognizing the
+// MakerNote.
+func detect(mk string, data []byte, order binary.ByteOrder) (Layout, error) {
+	decodersLock.RLock()
+	defer decodersLock.RUnlock()
+
+	for _, d := range decoders {
+		if layout, ok := d.Detect(mk, data, order); ok {
+			return layout, nil
+		}
+	}
+
+	return Layout{}, ErrUnknownVendor
+}
+
+// MakerNote is a parsed MakerNote.
+type MakerNote struct {
+	Vendor Vendor
+
+	// Make is the normalized make of the camera, and Model the model
+	// without the make.
+	Make  string
+	Model string
+
+	// Tiff holds the MakerNote IFD as IFD0. Entries read their values
+	// using the offset base of the vendor.
+	Tiff *tiff.Tiff
+
+	// Data is the raw MakerNote. It's nil if the MakerNote was stored
+	// as a separate TIFF structure, as in CR3 files.
+	Data []byte
+}
+
+// Parse detects the vendor of the MakerNote of e and parses its IFD.
+// The IFD is added to the IFD tree of e at exif.MakerNotePath, so e
+// must not be read concurrently while parsing. MakerNotes of unknown
+// vendors are not IFDs this package can read, and are left out.
+func Parse(e *exif.Exif) (*MakerNote, error) {
+	mk, model, _ := e.MakeModel()
+
+	// CR3 files store the MakerNote as a TIFF structure of its own.
+	if t, err := e.MakerNoteTiff(); err == nil {
+		return &MakerNote{Vendor: VendorCanon, Make: mk, Model: model, Tiff: t}, nil
+	}
+
+	entry, err := e.Entry(exif.AnyIFD, exif.MakerNote)
+	if err != nil {
+		return nil, ErrNoMakerNote
+	}
+
+	data, err := entry.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	// A MakerNote fitting in the value offset field can't hold an IFD.
+	if len(data) <= 8 {
+		return nil, ErrMalformed
+	}
+
+	layout, err := detect(mk, data, e.ByteOrder())
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := parseLayout(e.Reader(), int64(entry.Offset()), layout, e.ByteOrder())
+	if err != nil {
+		return nil, err
+	}
+
+	_ = e.GraftAt(exif.ExifPath, "MakerNote", t)
+
+	return &MakerNote{
+		Vendor: layout.Vendor,
+		Make:   mk,
+		Model:  model,
+		Tiff:   t,
+		Data:   data,
+	}, nil
+}
+
+// parseLayout parses the IFD of a MakerNote starting at offset in r,
+// the reader of the EXIF data.
+func parseLayout(r *io.SectionReader, offset int64, layout Layout, order binary.ByteOrder) (*tiff.Tiff, error) {
+	if layout.Order != nil {
+		order = layout.Order
+	}
+
+	size := r.Size()
+	start := offset + layout.IFDOffset
+
+	if start < 0 || start >= size {
+		return nil, ErrMalformed
+	}
+
+	switch layout.Base {
+	case BaseTIFF:
+		return tiff.ParseIFD(r, size, order, start)
+
+	case BaseMakerNote:
+		return tiff.ParseIFD(io.NewSectionReader(r, offset, size-offset), size-offset, order, layout.IFDOffset)
+
+	case BaseEmbedded:
+		return tiff.ParseReaderAt(io.NewSectionReader(r, start, size-start), size-start)
+
+	default:
+		return nil, ErrMalformed
+	}
+}
+
+// Entry returns the entry for the given tag in the MakerNote IFD.
+func (m *MakerNote) Entry(tag tiff.Tag) (tiff.Entry, error) {
+	return m.Tiff.Entry(0, tag)
+}
+
+// long returns the value of a single Long entry.
+func (m *MakerNote) long(tag tiff.Tag) (uint32, error) {
+	e, err := m.Entry(tag)
+	if err != nil {
+		return 0, err
+	}
+
+	return e.Long()
+}
+
+// short returns the value of a single Short entry.
+func (m *MakerNote) short(tag tiff.Tag) (uint16, error) {
+	e, err := m.Entry(tag)
+	if err != nil {
+		return 0, err
+	}
+
+	return e.Short()
+}
+
+// words returns the raw bytes of an entry as 16 bit words, regardless
+// of the type of the entry. Many vendors store records as arrays of
+// words, some using the Undefined type.
+func (m *MakerNote) words(tag tiff.Tag) ([]uint16, error) {
+	e, err := m.Entry(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := e.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	v := make([]uint16, len(buf)/2)
+	for i := range v {
+		v[i] = e.ByteOrder().Uint16(buf[2*i:])
+	}
+
+	return v, nil
+}
+
+// bytes returns the raw bytes of an entry in the MakerNote IFD.
+func (m *MakerNote) bytes(tag tiff.Tag) ([]byte, error) {
+	e, err := m.Entry(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.Bytes()
+}
+
+// cString returns data up to the first NUL byte, with surrounding
+// spaces removed.
+func cString(data []byte) string {
+	for i, b := range data {
+		if b == 0 {
+			data = data[:i]
+
+			break
+		}
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+// lensName returns a description of a lens, like "24-70mm f/2.8", from
+// the minimum and maximum focal length and the maximum aperture at
+// each.
+func lensName(lens [4]float64) string {
+	if lens[0] == 0 {
+		return ""
+	}
+
+	focal := strconv.FormatFloat(lens[0], 'f', -1, 64)
+	if lens[1] != lens[0] {
+		focal += "-" + strconv.FormatFloat(lens[1], 'f', -1, 64)
+	}
+
+	aperture := strconv.FormatFloat(lens[2], 'f', -1, 64)
+	if lens[3] != lens[2] {
+		aperture += "-" + strconv.FormatFloat(lens[3], 'f', -1, 64)
+	}
+
+	return focal + "mm f/" + aperture
+}
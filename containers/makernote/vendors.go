@@ -0,0 +1,173 @@
+package makernote
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+)
+
+// builtin are the built-in decoders, in order of detection. Decoders
+// recognizing a header come before decoders relying on the make alone.
+var builtin = []Decoder{
+	DecoderFunc(detectApple),
+	DecoderFunc(detectNikon),
+	DecoderFunc(detectFujifilm),
+	DecoderFunc(detectOlympus),
+	DecoderFunc(detectPanasonic),
+	DecoderFunc(detectPentax),
+	DecoderFunc(detectSony),
+	DecoderFunc(detectCanon),
+}
+
+// hasMake returns true if mk starts with prefix, ignoring case.
+func hasMake(mk string, prefix string) bool {
+	return strings.HasPrefix(strings.ToUpper(mk), strings.ToUpper(prefix))
+}
+
+// byteOrder returns the byte order given by a TIFF style "II" or "MM"
+// marker.
+func byteOrder(marker []byte) (binary.ByteOrder, bool) {
+	switch string(marker) {
+	case "II":
+		return binary.LittleEndian, true
+	case "MM":
+		return binary.BigEndian, true
+	default:
+		return nil, false
+	}
+}
+
+// detectApple recognizes iOS MakerNotes, starting with "Apple iOS"
+// and a big endian IFD at offset 14.
+func detectApple(_ string, data []byte, _ binary.ByteOrder) (Layout, bool) {
+	if !bytes.HasPrefix(data, []byte("Apple iOS\000")) {
+		return Layout{}, false
+	}
+
+	return Layout{Vendor: VendorApple, IFDOffset: 14, Base: BaseMakerNote, Order: binary.BigEndian}, true
+}
+
+// detectNikon recognizes the three Nikon layouts. Version 2 and later
+// embed a TIFF header after "Nikon\0" and a version. Version 1 holds
+// an IFD after the version, and early Coolpix models have no header.
+func detectNikon(mk string, data []byte, _ binary.ByteOrder) (Layout, bool) {
+	if bytes.HasPrefix(data, []byte("Nikon\000")) && len(data) > 10 {
+		if data[6] == 1 {
+			return Layout{Vendor: VendorNikon, IFDOffset: 8}, true
+		}
+
+		return Layout{Vendor: VendorNikon, IFDOffset: 10, Base: BaseEmbedded}, true
+	}
+
+	if hasMake(mk, "Nikon") {
+		return Layout{Vendor: VendorNikon}, true
+	}
+
+	return Layout{}, false
+}
+
+// detectFujifilm recognizes "FUJIFILM" followed by a little endian
+// offset of the IFD. Offsets are relative to the MakerNote. Some
+// other makes use the same layout.
+func detectFujifilm(_ string, data []byte, _ binary.ByteOrder) (Layout, bool) {
+	if !bytes.HasPrefix(data, []byte("FUJIFILM")) || len(data) < 12 {
+		return Layout{}, false
+	}
+
+	return Layout{
+		Vendor:    VendorFujifilm,
+		IFDOffset: int64(binary.LittleEndian.Uint32(data[8:])),
+		Base:      BaseMakerNote,
+		Order:     binary.LittleEndian,
+	}, true
+}
+
+// detectOlympus recognizes the Olympus and OM System layouts. The old
+// "OLYMP\0" layout uses offsets relative to the TIFF header, the newer
+// layouts are relative to the MakerNote and carry a byte order.
+func detectOlympus(_ string, data []byte, _ binary.ByteOrder) (Layout, bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte("OM SYSTEM\000")) && len(data) > 16:
+		order, ok := byteOrder(data[12:14])
+
+		return Layout{Vendor: VendorOlympus, IFDOffset: 16, Base: BaseMakerNote, Order: order}, ok
+
+	case bytes.HasPrefix(data, []byte("OLYMPUS\000")) && len(data) > 12:
+		order, ok := byteOrder(data[8:10])
+
+		return Layout{Vendor: VendorOlympus, IFDOffset: 12, Base: BaseMakerNote, Order: order}, ok
+
+	case bytes.HasPrefix(data, []byte("OLYMP\000")):
+		return Layout{Vendor: VendorOlympus, IFDOffset: 8}, true
+	}
+
+	return Layout{}, false
+}
+
+// detectPanasonic recognizes "Panasonic" followed by an IFD at offset
+// 12.
+func detectPanasonic(_ string, data []byte, _ binary.ByteOrder) (Layout, bool) {
+	if !bytes.HasPrefix(data, []byte("Panasonic\000")) {
+		return Layout{}, false
+	}
+
+	return Layout{Vendor: VendorPanasonic, IFDOffset: 12}, true
+}
+
+// detectPentax recognizes the "AOC\0" layout, with offsets relative to
+// the TIFF header, and the "PENTAX \0" layout, with offsets relative
+// to the MakerNote. Both carry a byte order.
+func detectPentax(_ string, data []byte, exifOrder binary.ByteOrder) (Layout, bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte("PENTAX \000")) && len(data) > 10:
+		order, ok := byteOrder(data[8:10])
+
+		return Layout{Vendor: VendorPentax, IFDOffset: 10, Base: BaseMakerNote, Order: order}, ok
+
+	case bytes.HasPrefix(data, []byte("AOC\000")) && len(data) > 6:
+		// Some models write spaces instead of a byte order.
+		order, ok := byteOrder(data[4:6])
+		if !ok {
+			order = exifOrder
+		}
+
+		return Layout{Vendor: VendorPentax, IFDOffset: 6, Order: order}, true
+	}
+
+	return Layout{}, false
+}
+
+// sonyHeaders are the headers found before the IFD in some Sony
+// MakerNotes. Newer models have no header.
+var sonyHeaders = []string{
+	"SONY DSC \000\000\000",
+	"SONY CAM \000\000\000",
+	"SONY MOBILE\000",
+	"VHAB     \000\000\000",
+}
+
+// detectSony recognizes Sony MakerNotes. Offsets are relative to the
+// TIFF header.
+func detectSony(mk string, data []byte, _ binary.ByteOrder) (Layout, bool) {
+	for _, header := range sonyHeaders {
+		if bytes.HasPrefix(data, []byte(header)) {
+			return Layout{Vendor: VendorSony, IFDOffset: int64(len(header))}, true
+		}
+	}
+
+	if hasMake(mk, "Sony") {
+		return Layout{Vendor: VendorSony}, true
+	}
+
+	return Layout{}, false
+}
+
+// detectCanon recognizes Canon MakerNotes by make. They have no
+// header, and offsets are relative to the TIFF header.
+func detectCanon(mk string, _ []byte, _ binary.ByteOrder) (Layout, bool) {
+	if !hasMake(mk, "Canon") {
+		return Layout{}, false
+	}
+
+	return Layout{Vendor: VendorCanon}, true
+}
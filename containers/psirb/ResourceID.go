@@ -0,0 +1,73 @@
+package psirb
+
+import (
+	"fmt"
+)
+
+// ResourceID identifies the content of an image resource.
+type ResourceID uint16
+
+var _ fmt.Stringer = ResourceID(0)
+
+const (
+	ResolutionInfo   ResourceID = 0x03ED
+	Caption          ResourceID = 0x03F0
+	IPTCNAA          ResourceID = 0x0404
+	JPEGQuality      ResourceID = 0x0406
+	GridAndGuides    ResourceID = 0x0408
+	ThumbnailOld     ResourceID = 0x0409
+	CopyrightFlag    ResourceID = 0x040A
+	URL              ResourceID = 0x040B
+	Thumbnail        ResourceID = 0x040C
+	GlobalAngle      ResourceID = 0x040D
+	ICCProfile       ResourceID = 0x040F
+	IDsBaseValue     ResourceID = 0x0414
+	UnicodeAlphaName ResourceID = 0x0415
+	GlobalAltitude   ResourceID = 0x0419
+	Slices           ResourceID = 0x041A
+	URLList          ResourceID = 0x041E
+	VersionInfo      ResourceID = 0x0421
+	ExifData1        ResourceID = 0x0422
+	ExifData3        ResourceID = 0x0423
+	XMPMetadata      ResourceID = 0x0424
+	CaptionDigest    ResourceID = 0x0425
+	PrintScale       ResourceID = 0x0426
+	PixelAspectRatio ResourceID = 0x0428
+	PrintFlagsInfo   ResourceID = 0x2710
+)
+
+// String returns a string representation of the resource ID.
+func (id ResourceID) String() string {
+	m := map[ResourceID]string{
+		ResolutionInfo:   "ResolutionInfo",
+		Caption:          "Caption",
+		IPTCNAA:          "IPTCNAA",
+		JPEGQuality:      "JPEGQuality",
+		GridAndGuides:    "GridAndGuides",
+		ThumbnailOld:     "ThumbnailOld",
+		CopyrightFlag:    "CopyrightFlag",
+		URL:              "URL",
+		Thumbnail:        "Thumbnail",
+		GlobalAngle:      "GlobalAngle",
+		ICCProfile:       "ICCProfile",
+		IDsBaseValue:     "IDsBaseValue",
+		UnicodeAlphaName: "UnicodeAlphaName",
+		GlobalAltitude:   "GlobalAltitude",
+		Slices:           "Slices",
+		URLList:          "URLList",
+		VersionInfo:      "VersionInfo",
+		ExifData1:        "ExifData1",
+		ExifData3:        "ExifData3",
+		XMPMetadata:      "XMPMetadata",
+		CaptionDigest:    "CaptionDigest",
+		PrintScale:       "PrintScale",
+		PixelAspectRatio: "PixelAspectRatio",
+		PrintFlagsInfo:   "PrintFlagsInfo",
+	}
+
+	if s, ok := m[id]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("UNKNOWN:%04x", uint16(id))
+}
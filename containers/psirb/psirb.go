@@ -0,0 +1,120 @@
+package psirb
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/abrander/apexif/containers/iptc"
+)
+
+var (
+	// ErrNoResourcesFound is returned if a file has no image resources.
+	ErrNoResourcesFound = errors.New("no Photoshop image resources found")
+
+	// ErrResourceNotFound is returned if a resource is not present.
+	ErrResourceNotFound = errors.New("resource not found")
+
+	// ErrMalformed is returned if a resource block is truncated or
+	// lacks a known signature.
+	ErrMalformed = errors.New("malformed image resource block")
+)
+
+// Signature is the signature starting the APP13 payload in JPEG files.
+const Signature = "Photoshop 3.0\000"
+
+// signatures are the resource signatures accepted. Everything but 8BIM
+// is rare, but seen in the wild.
+var signatures = map[string]bool{
+	"8BIM": true,
+	"MeSa": true,
+	"PHUT": true,
+	"AgHg": true,
+	"DCSR": true,
+}
+
+// Resource is a single image resource.
+type Resource struct {
+	ID   ResourceID
+	Name string
+	Data []byte
+}
+
+// Resources is a list of image resources in file order.
+type Resources []Resource
+
+// Parse parses a sequence of image resources. Resources are padded to
+// an even length. Any resources parsed before an error is encountered
+// are returned along with the error.
+func Parse(data []byte) (Resources, error) {
+	var resources Resources
+
+	offset := 0
+
+	for offset < len(data) {
+		// Signature, ID and the length byte of the name.
+		if offset+7 > len(data) {
+			return resources, ErrMalformed
+		}
+
+		if !signatures[string(data[offset:offset+4])] {
+			return resources, ErrMalformed
+		}
+
+		id := ResourceID(binary.BigEndian.Uint16(data[offset+4:]))
+
+		// The name is a Pascal string padded to an even size
+		// including the length byte.
+		nameLength := int(data[offset+6])
+		nameStart := offset + 7
+		offset = nameStart + nameLength
+		if nameLength%2 == 0 {
+			offset++
+		}
+
+		if offset+4 > len(data) {
+			return resources, ErrMalformed
+		}
+
+		name := string(data[nameStart : nameStart+nameLength])
+
+		size := int(binary.BigEndian.Uint32(data[offset:]))
+		offset += 4
+
+		if size < 0 || size > len(data)-offset {
+			return resources, ErrMalformed
+		}
+
+		resources = append(resources, Resource{
+			ID:   id,
+			Name: name,
+			Data: data[offset : offset+size],
+		})
+
+		offset += size + size%2
+	}
+
+	return resources, nil
+}
+
+// Resource returns the first resource with the given ID or
+// ErrResourceNotFound.
+func (rs Resources) Resource(id ResourceID) (*Resource, error) {
+	for i := range rs {
+		if rs[i].ID == id {
+			return &rs[i], nil
+		}
+	}
+
+	return nil, ErrResourceNotFound
+}
+
+// IPTC parses the IPTC-NAA resource. If not present,
+// iptc.ErrNoIPTCFound is returned.
+func (rs Resources) IPTC() (*iptc.IPTC, error) {
+	r, err := rs.Resource(IPTCNAA)
+	if err != nil {
+		return nil, iptc.ErrNoIPTCFound
+	}
+
+	return iptc.Parse(r.Data)
+}
@@ -3,6 +3,7 @@ package riff
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 )
 
 // Riff is a type representing a RIFF container.
@@ -104,6 +105,67 @@ func Parse(data []byte) (*Riff, error) {
 	return r, nil
 }
 
+// ChunkHeader is a type representing the header of a chunk read from
+// an io.ReaderAt. The chunk data itself is not read.
+type ChunkHeader struct {
+	Identifier string
+	Length     uint32
+
+	// Offset is the offset of the chunk data in the reader.
+	Offset int64
+}
+
+// ReadChunkHeaders reads the headers of the chunks found between
+// offset and end in r. If a chunk is truncated, the headers read so far
+// are returned along with the error.
+func ReadChunkHeaders(r io.ReaderAt, offset int64, end int64) ([]ChunkHeader, error) {
+	var headers []ChunkHeader
+
+	buf := make([]byte, 8)
+
+	for offset < end {
+		if end-offset < 8 {
+			return headers, fmt.Errorf("not enough data for chunk header, got %d bytes", end-offset)
+		}
+
+		n, err := r.ReadAt(buf, offset)
+		if n < len(buf) {
+			return headers, err
+		}
+
+		header := ChunkHeader{
+			Identifier: string(buf[0:4]),
+			Length:     binary.LittleEndian.Uint32(buf[4:8]),
+			Offset:     offset + 8,
+		}
+
+		if end-header.Offset < int64(header.Length) {
+			return headers, fmt.Errorf("not enough data for chunk")
+		}
+
+		headers = append(headers, header)
+
+		offset = header.Offset + int64(header.Length)
+		if header.Length%2 == 1 {
+			offset++
+		}
+	}
+
+	return headers, nil
+}
+
+// Data reads the chunk data from r.
+func (h ChunkHeader) Data(r io.ReaderAt) ([]byte, error) {
+	buf := make([]byte, h.Length)
+
+	n, err := r.ReadAt(buf, h.Offset)
+	if n < len(buf) {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
 func (r *Riff) Chunks() []Chunk {
 	return r.chunks
 }
@@ -1,17 +1,21 @@
 package tiff
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 )
 
-// Entry is a type representing an IFD entry.
+// Entry is a type representing an IFD entry. ValueOffset holds either
+// the value itself, if it fits, or the offset of the value. Classic
+// TIFF only uses the first 4 bytes, BigTIFF all 8.
 type Entry struct {
 	Tag         Tag
 	Type        Type
 	Count       uint32
-	ValueOffset [4]byte
+	ValueOffset [8]byte
 
 	tiff *Tiff
 }
@@ -84,6 +88,16 @@ func (e Entry) String() string {
 			str = fmt.Sprintf("SRational(%d):[%v]", len(r), r)
 		}
 
+	case e.Type == Undefined:
+		str = fmt.Sprintf("Undefined(%d)", e.Count)
+
+	case e.Type.Size() > 0:
+		var v any
+		v, err = e.Value()
+		if err == nil {
+			str = fmt.Sprintf("%s(%d):[%v]", e.Type, e.Count, v)
+		}
+
 	default:
 		str = fmt.Sprintf("ERR %04x %s(%d): %d/%08x", int(e.Tag), e.Type, e.Count, e.Offset(), e.ValueOffset)
 	}
@@ -131,8 +145,22 @@ func (e Entry) Value() (any, error) {
 
 		return e.RationalSlice()
 
+	case SByte:
+		if e.Count == 1 {
+			return e.SByte()
+		}
+
+		return e.SByteSlice()
+
 	case Undefined:
-		return nil, errors.New("Undefined type")
+		return e.UndefinedBytes()
+
+	case SShort:
+		if e.Count == 1 {
+			return e.SShort()
+		}
+
+		return e.SShortSlice()
 
 	case SLong:
 		if e.Count == 1 {
@@ -148,6 +176,41 @@ func (e Entry) Value() (any, error) {
 
 		return e.SRationalSlice()
 
+	case Float:
+		if e.Count == 1 {
+			return e.Float32()
+		}
+
+		return e.Float32Slice()
+
+	case Double:
+		if e.Count == 1 {
+			return e.Float64()
+		}
+
+		return e.Float64Slice()
+
+	case IFDType, IFD8Type:
+		if e.Count == 1 {
+			return e.IFDOffset()
+		}
+
+		return e.IFDOffsets()
+
+	case Long8:
+		if e.Count == 1 {
+			return e.Long8()
+		}
+
+		return e.Long8Slice()
+
+	case SLong8:
+		if e.Count == 1 {
+			return e.SLong8()
+		}
+
+		return e.SLong8Slice()
+
 	default:
 		return nil, fmt.Errorf("Unknown type: %s", e.Type)
 	}
@@ -159,39 +222,52 @@ func (e Entry) Value() (any, error) {
 func (e *Entry) Int() (int, error) {
 	switch e.Type {
 	case Ascii:
-		if e.Count == 1 {
-			return int(e.ValueOffset[0]), nil
+		buf, err := e.single(Ascii)
+		if err != nil {
+			return 0, err
 		}
 
-		return 0, errors.New("Not single ascii character")
+		return int(buf[0]), nil
 
 	case Byte:
-		if e.Count == 1 {
-			return int(e.ValueOffset[0]), nil
-		}
+		v, err := e.Byte()
 
-		return 0, errors.New("Not single byte")
+		return int(v), err
 
 	case Short:
-		if e.Count == 1 {
-			return int(e.tiff.endianness.Uint16(e.ValueOffset[:])), nil
-		}
+		v, err := e.Short()
 
-		return 0, errors.New("Not single short")
+		return int(v), err
 
 	case Long:
-		if e.Count == 1 {
-			return int(e.tiff.endianness.Uint32(e.ValueOffset[:])), nil
-		}
+		v, err := e.Long()
 
-		return 0, errors.New("Not single long")
+		return int(v), err
 
 	case SLong:
-		if e.Count == 1 {
-			return int(e.tiff.endianness.Uint32(e.ValueOffset[:])), nil
-		}
+		v, err := e.SLong()
+
+		return int(v), err
 
-		return 0, errors.New("Not single signed long")
+	case SByte:
+		v, err := e.SByte()
+
+		return int(v), err
+
+	case SShort:
+		v, err := e.SShort()
+
+		return int(v), err
+
+	case Long8:
+		v, err := e.Long8()
+
+		return int(v), err
+
+	case SLong8:
+		v, err := e.SLong8()
+
+		return int(v), err
 
 	default:
 		return 0, errors.New("Not an integer like value")
@@ -231,113 +307,135 @@ func (e *Entry) Float() (float64, error) {
 
 		return 0, errors.New("Not single signed rational")
 
+	case Float:
+		f, err := e.Float32()
+
+		return float64(f), err
+
+	case Double:
+		return e.Float64()
+
 	default:
 		return 0, errors.New("Not a float like value")
 	}
 }
 
+// ByteOrder returns the byte order of the values of the entry.
+func (e Entry) ByteOrder() binary.ByteOrder {
+	return e.tiff.endianness
+}
+
 // Offset returns the offset of the entry.
 func (e Entry) Offset() int {
+	if e.tiff.big {
+		return int(e.tiff.endianness.Uint64(e.ValueOffset[:]))
+	}
+
 	return int(e.tiff.endianness.Uint32(e.ValueOffset[:]))
 }
 
-// Byte returns the byte value of the entry. If the entry
-// is not a single byte, an error is returned.
-func (e *Entry) Byte() (byte, error) {
-	if e.Type != Byte {
-		return 0, errors.New("Not byte")
+// inline returns true if the values of the entry, each taking up size
+// bytes, are stored in the value offset field.
+func (e *Entry) inline(size int64) bool {
+	return size*int64(e.Count) <= e.tiff.valueSize()
+}
+
+// Bytes returns the raw bytes of the values of the entry, the size of
+// the type times the count. Values are stored in the value offset
+// field if they fit, otherwise the field holds the offset of the
+// values. The bytes are in the byte order of the file.
+func (e *Entry) Bytes() ([]byte, error) {
+	size := e.Type.Size()
+	if size == 0 {
+		return nil, fmt.Errorf("Unknown type: %s", e.Type)
 	}
 
-	if e.Count != 1 {
-		return 0, errors.New("Not single byte")
+	length := size * int64(e.Count)
+
+	if e.inline(size) {
+		buf := make([]byte, length)
+		copy(buf, e.ValueOffset[:])
+
+		return buf, nil
 	}
 
-	return e.ValueOffset[0], nil
+	return e.tiff.read(int64(e.Offset()), length)
 }
 
-// ByteSlice returns the byte slice value of the entry.
-func (e *Entry) ByteSlice() ([]byte, error) {
-	if e.Type != Byte {
-		return nil, errors.New("Not byte")
+// values returns the raw bytes of the values of the entry if it's of
+// type t.
+func (e *Entry) values(t Type) ([]byte, error) {
+	if e.Type != t {
+		return nil, fmt.Errorf("not %s", t)
 	}
 
-	if e.Count <= 4 {
-		// String is stored in the value offset
-		return []byte(e.ValueOffset[:e.Count]), nil
-	}
+	return e.Bytes()
+}
 
-	if len(e.tiff.bytes) < e.Offset()+int(e.Count) {
-		return nil, errors.New("Buffer too small")
+// single returns the raw bytes of the value of the entry if it's a
+// single value of type t.
+func (e *Entry) single(t Type) ([]byte, error) {
+	if e.Count != 1 {
+		return nil, fmt.Errorf("not a single %s", t)
 	}
 
-	return e.tiff.bytes[e.Offset() : e.Offset()+int(e.Count)], nil
+	return e.values(t)
 }
 
-// Ascii returns the ASCII value of the entry.
-func (e *Entry) Ascii() (string, error) {
-	clean := func(str string) string {
-		str = strings.TrimSuffix(str, "\x00")
-		str = strings.TrimSpace(str)
-
-		return str
+// Byte returns the byte value of the entry. If the entry
+// is not a single byte, an error is returned.
+func (e *Entry) Byte() (byte, error) {
+	buf, err := e.single(Byte)
+	if err != nil {
+		return 0, err
 	}
 
-	if e.Type != Ascii {
-		return "", errors.New("Not ascii")
-	}
+	return buf[0], nil
+}
 
-	if e.Count <= 4 {
-		// String is stored in the value offset
-		return clean(string(e.ValueOffset[:e.Count])), nil
+// ByteSlice returns the byte slice value of the entry. Undefined
+// entries are returned as is too.
+func (e *Entry) ByteSlice() ([]byte, error) {
+	if e.Type == Undefined {
+		return e.Bytes()
 	}
 
-	if len(e.tiff.bytes) < e.Offset()+int(e.Count) {
-		return "", errors.New("Buffer too small")
+	return e.values(Byte)
+}
+
+// Ascii returns the ASCII value of the entry.
+func (e *Entry) Ascii() (string, error) {
+	buf, err := e.values(Ascii)
+	if err != nil {
+		return "", err
 	}
 
-	str := string(e.tiff.bytes[e.Offset() : e.Offset()+int(e.Count)])
+	str := strings.TrimSuffix(string(buf), "\x00")
+	str = strings.TrimSpace(str)
 
-	return clean(str), nil
+	return str, nil
 }
 
 // Short returns the unsigned short value of the entry.
 func (e *Entry) Short() (uint16, error) {
-	if e.Type != Short {
-		return 0, errors.New("not a short")
-	}
-
-	if e.Count != 1 {
-		return 0, errors.New("not a single short")
+	buf, err := e.single(Short)
+	if err != nil {
+		return 0, err
 	}
 
-	return e.tiff.endianness.Uint16(e.ValueOffset[:]), nil
+	return e.tiff.endianness.Uint16(buf), nil
 }
 
 // ShortSlice returns the unsigned short slice value of the entry.
 func (e *Entry) ShortSlice() ([]uint16, error) {
-	if e.Type != Short {
-		return nil, errors.New("not a short")
-	}
-
-	if e.Count <= 2 {
-		// Short is stored in the value offset
-		shorts := make([]uint16, e.Count)
-
-		for i := 0; i < int(e.Count); i++ {
-			shorts[i] = e.tiff.endianness.Uint16(e.ValueOffset[i*2:])
-		}
-
-		return shorts, nil
-	}
-
-	if len(e.tiff.bytes) < e.Offset()+int(e.Count)*2 {
-		return nil, errors.New("buffer too small")
+	buf, err := e.values(Short)
+	if err != nil {
+		return nil, err
 	}
 
 	shorts := make([]uint16, e.Count)
-
-	for i := 0; i < int(e.Count); i++ {
-		shorts[i] = e.tiff.endianness.Uint16(e.tiff.bytes[e.Offset()+2*i:])
+	for i := range shorts {
+		shorts[i] = e.tiff.endianness.Uint16(buf[2*i:])
 	}
 
 	return shorts, nil
@@ -345,206 +443,318 @@ func (e *Entry) ShortSlice() ([]uint16, error) {
 
 // Long returns the unsigned long (32 bit) value of the entry.
 func (e *Entry) Long() (uint32, error) {
-	if e.Type != Long {
-		return 0, errors.New("not a long")
+	buf, err := e.single(Long)
+	if err != nil {
+		return 0, err
 	}
 
-	if e.Count != 1 {
-		return 0, errors.New("not a single long")
+	return e.tiff.endianness.Uint32(buf), nil
+}
+
+// LongSlice returns the unsigned long slice value of the entry.
+func (e *Entry) LongSlice() ([]uint32, error) {
+	buf, err := e.values(Long)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(e.tiff.bytes) < e.Offset()+4 {
-		return 0, errors.New("buffer too small")
+	longs := make([]uint32, e.Count)
+	for i := range longs {
+		longs[i] = e.tiff.endianness.Uint32(buf[4*i:])
 	}
 
-	return e.tiff.endianness.Uint32(e.ValueOffset[:]), nil
+	return longs, nil
 }
 
-// LongSlice returns the unsigned long slice value of the entry.
-func (e *Entry) LongSlice() ([]uint32, error) {
-	if e.Type != Long {
-		return nil, errors.New("not a long")
+// Rational returns the unsigned rational value of the entry.
+func (e *Entry) Rational() (UnsignedRational, error) {
+	buf, err := e.single(Rational)
+	if err != nil {
+		return UnsignedRational{}, err
 	}
 
-	if e.Count <= 1 {
-		// Long is stored in the value offset
-		longs := make([]uint32, e.Count)
+	return e.rational(buf), nil
+}
 
-		for i := 0; i < int(e.Count); i++ {
-			longs[i] = e.tiff.endianness.Uint32(e.ValueOffset[i*4:])
-		}
+// RationalSlice returns the unsigned rational slice value of the entry.
+func (e *Entry) RationalSlice() ([]UnsignedRational, error) {
+	buf, err := e.values(Rational)
+	if err != nil {
+		return nil, err
+	}
 
-		return longs, nil
+	ratios := make([]UnsignedRational, e.Count)
+	for i := range ratios {
+		ratios[i] = e.rational(buf[8*i:])
 	}
 
-	if len(e.tiff.bytes) < e.Offset()+int(e.Count)*4 {
-		return nil, errors.New("buffer too small")
+	return ratios, nil
+}
+
+// rational decodes an unsigned rational from the first 8 bytes of buf.
+func (e *Entry) rational(buf []byte) UnsignedRational {
+	return UnsignedRational{
+		Numerator:   e.tiff.endianness.Uint32(buf[0:]),
+		Denominator: e.tiff.endianness.Uint32(buf[4:]),
 	}
+}
 
-	longs := make([]uint32, e.Count)
+// SLong returns the signed long (32 bit) value of the entry.
+func (e *Entry) SLong() (int32, error) {
+	buf, err := e.single(SLong)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(e.tiff.endianness.Uint32(buf)), nil
+}
+
+// SLongSlice returns the signed long slice value of the entry.
+func (e *Entry) SLongSlice() ([]int32, error) {
+	buf, err := e.values(SLong)
+	if err != nil {
+		return nil, err
+	}
 
-	for i := 0; i < int(e.Count); i++ {
-		longs[i] = e.tiff.endianness.Uint32(e.tiff.bytes[e.Offset()+4*i:])
+	longs := make([]int32, e.Count)
+	for i := range longs {
+		longs[i] = int32(e.tiff.endianness.Uint32(buf[4*i:]))
 	}
 
 	return longs, nil
 }
 
-// Rational returns the unsigned rational value of the entry.
-func (e *Entry) Rational() (UnsignedRational, error) {
-	if e.Type != Rational {
-		return UnsignedRational{}, errors.New("not a rational")
+// SRational returns the signed rational value of the entry.
+func (e *Entry) SRational() (SignedRational, error) {
+	buf, err := e.single(SRational)
+	if err != nil {
+		return SignedRational{}, err
 	}
 
-	if e.Count != 1 {
-		return UnsignedRational{}, errors.New("not a single rational")
+	return e.sRational(buf), nil
+}
+
+// SRationalSlice returns the signed rational slice value of the entry.
+func (e *Entry) SRationalSlice() ([]SignedRational, error) {
+	buf, err := e.values(SRational)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(e.tiff.bytes) < e.Offset()+8 {
-		return UnsignedRational{}, errors.New("buffer too small")
+	ratios := make([]SignedRational, e.Count)
+	for i := range ratios {
+		ratios[i] = e.sRational(buf[8*i:])
 	}
 
-	return UnsignedRational{
-		Numerator:   e.tiff.endianness.Uint32(e.tiff.bytes[e.Offset():]),
-		Denominator: e.tiff.endianness.Uint32(e.tiff.bytes[e.Offset()+4:]),
-	}, nil
+	return ratios, nil
 }
 
-// RationalSlice returns the unsigned rational slice value of the entry.
-func (e *Entry) RationalSlice() ([]UnsignedRational, error) {
-	if e.Type != Rational {
-		return nil, errors.New("not a rational")
+// sRational decodes a signed rational from the first 8 bytes of buf.
+func (e *Entry) sRational(buf []byte) SignedRational {
+	return SignedRational{
+		Numerator:   int32(e.tiff.endianness.Uint32(buf[0:])),
+		Denominator: int32(e.tiff.endianness.Uint32(buf[4:])),
 	}
+}
 
-	if e.Count <= 1 {
-		// Rational is stored in the value offset
-		ratios := make([]UnsignedRational, e.Count)
+// SByte returns the signed byte value of the entry.
+func (e *Entry) SByte() (int8, error) {
+	buf, err := e.single(SByte)
+	if err != nil {
+		return 0, err
+	}
 
-		for i := 0; i < int(e.Count); i++ {
-			ratios[i] = UnsignedRational{
-				Numerator:   e.tiff.endianness.Uint32(e.ValueOffset[i*8:]),
-				Denominator: e.tiff.endianness.Uint32(e.ValueOffset[i*8+4:]),
-			}
-		}
+	return int8(buf[0]), nil
+}
 
-		return ratios, nil
+// SByteSlice returns the signed byte slice value of the entry.
+func (e *Entry) SByteSlice() ([]int8, error) {
+	buf, err := e.values(SByte)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(e.tiff.bytes) < e.Offset()+int(e.Count)*8 {
-		return nil, errors.New("buffer too small")
+	bytes := make([]int8, len(buf))
+	for i, b := range buf {
+		bytes[i] = int8(b)
 	}
 
-	ratios := make([]UnsignedRational, e.Count)
+	return bytes, nil
+}
 
-	for i := 0; i < int(e.Count); i++ {
-		ratios[i] = UnsignedRational{
-			Numerator:   e.tiff.endianness.Uint32(e.tiff.bytes[e.Offset()+8*i:]),
-			Denominator: e.tiff.endianness.Uint32(e.tiff.bytes[e.Offset()+8*i+4:]),
-		}
+// UndefinedBytes returns the raw bytes of an Undefined entry. The
+// interpretation depends on the tag.
+func (e *Entry) UndefinedBytes() ([]byte, error) {
+	return e.values(Undefined)
+}
+
+// SShort returns the signed short value of the entry.
+func (e *Entry) SShort() (int16, error) {
+	buf, err := e.single(SShort)
+	if err != nil {
+		return 0, err
 	}
 
-	return ratios, nil
+	return int16(e.tiff.endianness.Uint16(buf)), nil
 }
 
-// SLong returns the signed long (32 bit) value of the entry.
-func (e *Entry) SLong() (int32, error) {
-	if e.Type != SLong {
-		return 0, errors.New("not a long")
+// SShortSlice returns the signed short slice value of the entry.
+func (e *Entry) SShortSlice() ([]int16, error) {
+	buf, err := e.values(SShort)
+	if err != nil {
+		return nil, err
 	}
 
-	if e.Count != 1 {
-		return 0, errors.New("not a single long")
+	shorts := make([]int16, e.Count)
+	for i := range shorts {
+		shorts[i] = int16(e.tiff.endianness.Uint16(buf[2*i:]))
 	}
 
-	if len(e.tiff.bytes) < e.Offset()+4 {
-		return 0, errors.New("buffer too small")
+	return shorts, nil
+}
+
+// Float32 returns the single precision float value of the entry.
+func (e *Entry) Float32() (float32, error) {
+	buf, err := e.single(Float)
+	if err != nil {
+		return 0, err
 	}
 
-	return int32(e.tiff.endianness.Uint32(e.ValueOffset[:])), nil
+	return math.Float32frombits(e.tiff.endianness.Uint32(buf)), nil
 }
 
-// SLongSlice returns the signed long slice value of the entry.
-func (e *Entry) SLongSlice() ([]int32, error) {
-	if e.Type != SLong {
-		return nil, errors.New("not a signed long")
+// Float32Slice returns the single precision float slice value of the
+// entry.
+func (e *Entry) Float32Slice() ([]float32, error) {
+	buf, err := e.values(Float)
+	if err != nil {
+		return nil, err
 	}
 
-	if e.Count <= 1 {
-		// Long is stored in the value offset
-		longs := make([]int32, e.Count)
+	floats := make([]float32, e.Count)
+	for i := range floats {
+		floats[i] = math.Float32frombits(e.tiff.endianness.Uint32(buf[4*i:]))
+	}
 
-		for i := 0; i < int(e.Count); i++ {
-			longs[i] = int32(e.tiff.endianness.Uint32(e.ValueOffset[i*4:]))
-		}
+	return floats, nil
+}
 
-		return longs, nil
+// Float64 returns the double precision float value of the entry.
+func (e *Entry) Float64() (float64, error) {
+	buf, err := e.single(Double)
+	if err != nil {
+		return 0, err
 	}
 
-	if len(e.tiff.bytes) < e.Offset()+int(e.Count)*4 {
-		return nil, errors.New("buffer too small")
+	return math.Float64frombits(e.tiff.endianness.Uint64(buf)), nil
+}
+
+// Float64Slice returns the double precision float slice value of the
+// entry.
+func (e *Entry) Float64Slice() ([]float64, error) {
+	buf, err := e.values(Double)
+	if err != nil {
+		return nil, err
 	}
 
-	longs := make([]int32, e.Count)
+	floats := make([]float64, e.Count)
+	for i := range floats {
+		floats[i] = math.Float64frombits(e.tiff.endianness.Uint64(buf[8*i:]))
+	}
+
+	return floats, nil
+}
+
+// Long8 returns the unsigned 64 bit value of the entry.
+func (e *Entry) Long8() (uint64, error) {
+	buf, err := e.single(Long8)
+	if err != nil {
+		return 0, err
+	}
 
-	for i := 0; i < int(e.Count); i++ {
-		longs[i] = int32(e.tiff.endianness.Uint32(e.tiff.bytes[e.Offset()+4*i:]))
+	return e.tiff.endianness.Uint64(buf), nil
+}
+
+// Long8Slice returns the unsigned 64 bit slice value of the entry.
+func (e *Entry) Long8Slice() ([]uint64, error) {
+	buf, err := e.values(Long8)
+	if err != nil {
+		return nil, err
+	}
+
+	longs := make([]uint64, e.Count)
+	for i := range longs {
+		longs[i] = e.tiff.endianness.Uint64(buf[8*i:])
 	}
 
 	return longs, nil
 }
 
-// SRational returns the signed rational value of the entry.
-func (e *Entry) SRational() (SignedRational, error) {
-	if e.Type != SRational {
-		return SignedRational{}, errors.New("not a rational")
+// SLong8 returns the signed 64 bit value of the entry.
+func (e *Entry) SLong8() (int64, error) {
+	buf, err := e.single(SLong8)
+	if err != nil {
+		return 0, err
 	}
 
-	if e.Count != 1 {
-		return SignedRational{}, errors.New("not a single rational")
+	return int64(e.tiff.endianness.Uint64(buf)), nil
+}
+
+// SLong8Slice returns the signed 64 bit slice value of the entry.
+func (e *Entry) SLong8Slice() ([]int64, error) {
+	buf, err := e.values(SLong8)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(e.tiff.bytes) < e.Offset()+8 {
-		return SignedRational{}, errors.New("buffer too small")
+	longs := make([]int64, e.Count)
+	for i := range longs {
+		longs[i] = int64(e.tiff.endianness.Uint64(buf[8*i:]))
 	}
 
-	return SignedRational{
-		Numerator:   int32(e.tiff.endianness.Uint32(e.tiff.bytes[e.Offset():])),
-		Denominator: int32(e.tiff.endianness.Uint32(e.tiff.bytes[e.Offset()+4:])),
-	}, nil
+	return longs, nil
 }
 
-// SRationalSlice returns the signed rational slice value of the entry.
-func (e *Entry) SRationalSlice() ([]SignedRational, error) {
-	if e.Type != SRational {
-		return nil, errors.New("not a rational")
+// IFDOffset returns the IFD offset held by the entry. Besides the IFD
+// and IFD8 types, a Long is accepted, as most writers use that for
+// IFD pointers.
+func (e *Entry) IFDOffset() (int64, error) {
+	if e.Count != 1 {
+		return 0, errors.New("not a single IFD offset")
 	}
 
-	if e.Count <= 1 {
-		// Rational is stored in the value offset
-		ratios := make([]SignedRational, e.Count)
+	offsets, err := e.IFDOffsets()
+	if err != nil {
+		return 0, err
+	}
 
-		for i := 0; i < int(e.Count); i++ {
-			ratios[i] = SignedRational{
-				Numerator:   int32(e.tiff.endianness.Uint32(e.ValueOffset[i*8:])),
-				Denominator: int32(e.tiff.endianness.Uint32(e.ValueOffset[i*8+4:])),
-			}
-		}
+	return offsets[0], nil
+}
 
-		return ratios, nil
+// IFDOffsets returns the IFD offsets held by the entry, like the
+// SubIFDs tag. See IFDOffset.
+func (e *Entry) IFDOffsets() ([]int64, error) {
+	switch e.Type {
+	case IFDType, Long, IFD8Type, Long8:
+	default:
+		return nil, errors.New("not an IFD offset")
 	}
 
-	if len(e.tiff.bytes) < e.Offset()+int(e.Count)*8 {
-		return nil, errors.New("buffer too small")
+	buf, err := e.Bytes()
+	if err != nil {
+		return nil, err
 	}
 
-	ratios := make([]SignedRational, e.Count)
+	size := e.Type.Size()
+	offsets := make([]int64, e.Count)
 
-	for i := 0; i < int(e.Count); i++ {
-		ratios[i] = SignedRational{
-			Numerator:   int32(e.tiff.endianness.Uint32(e.tiff.bytes[e.Offset()+8*i:])),
-			Denominator: int32(e.tiff.endianness.Uint32(e.tiff.bytes[e.Offset()+8*i+4:])),
+	for i := range offsets {
+		if size == 8 {
+			offsets[i] = int64(e.tiff.endianness.Uint64(buf[8*i:]))
+		} else {
+			offsets[i] = int64(e.tiff.endianness.Uint32(buf[4*i:]))
 		}
 	}
 
-	return ratios, nil
+	return offsets, nil
 }
@@ -0,0 +1,248 @@
+package tiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// buildEntry returns a TIFF file with a single entry in IFD0. value is
+// written with binary.Write in the given byte order, in the value
+// offset field if it fits and after the IFD otherwise.
+func buildEntry(t *testing.T, order binary.ByteOrder, big bool, typ Type, count uint32, value any) []byte {
+	t.Helper()
+
+	var v bytes.Buffer
+	if value != nil {
+		err := binary.Write(&v, order, value)
+		if err != nil {
+			t.Fatalf("binary.Write: %s", err)
+		}
+	}
+
+	var b bytes.Buffer
+
+	w := func(data any) {
+		_ = binary.Write(&b, order, data)
+	}
+
+	if order == binary.ByteOrder(binary.LittleEndian) {
+		b.WriteString("II")
+	} else {
+		b.WriteString("MM")
+	}
+
+	valueSize := 4
+	if big {
+		// Header, IFD count and the entry up to the value offset.
+		w(uint16(bigMagic))
+		w(uint16(8))
+		w(uint16(0))
+		w(uint64(16))
+		w(uint64(1))
+		w(uint16(0x1234))
+		w(uint16(typ))
+		w(uint64(count))
+
+		valueSize = 8
+	} else {
+		w(uint16(magic))
+		w(uint32(8))
+		w(uint16(1))
+		w(uint16(0x1234))
+		w(uint16(typ))
+		w(count)
+	}
+
+	field := make([]byte, valueSize)
+	data := v.Bytes()
+	end := b.Len() + 2*valueSize
+
+	if len(data) <= valueSize {
+		copy(field, data)
+	} else if big {
+		order.PutUint64(field, uint64(end))
+	} else {
+		order.PutUint32(field, uint32(end))
+	}
+
+	b.Write(field)
+	b.Write(make([]byte, valueSize))
+
+	if len(data) > valueSize {
+		b.Write(data)
+	}
+
+	return b.Bytes()
+}
+
+func TestEntry(t *testing.T) {
+	cases := []struct {
+		name  string
+		typ   Type
+		count uint32
+		value any
+
+		// inline is the expected placement of the value in classic TIFF
+		// and BigTIFF.
+		inline [2]bool
+
+		get  func(e *Entry) (any, error)
+		want any
+	}{
+		{
+			name: "Short", typ: Short, count: 1, value: uint16(0xbeef),
+			inline: [2]bool{true, true},
+			get:    func(e *Entry) (any, error) { return e.Short() },
+			want:   uint16(0xbeef),
+		},
+		{
+			name: "ShortSlice 2", typ: Short, count: 2, value: []uint16{1, 0xbeef},
+			inline: [2]bool{true, true},
+			get:    func(e *Entry) (any, error) { return e.ShortSlice() },
+			want:   []uint16{1, 0xbeef},
+		},
+		{
+			name: "ShortSlice 4", typ: Short, count: 4, value: []uint16{1, 2, 3, 0xbeef},
+			inline: [2]bool{false, true},
+			get:    func(e *Entry) (any, error) { return e.ShortSlice() },
+			want:   []uint16{1, 2, 3, 0xbeef},
+		},
+		{
+			// The value is inline, so it must not be checked as an
+			// offset into the file.
+			name: "Long", typ: Long, count: 1, value: uint32(0xfffffff0),
+			inline: [2]bool{true, true},
+			get:    func(e *Entry) (any, error) { return e.Long() },
+			want:   uint32(0xfffffff0),
+		},
+		{
+			name: "SLong", typ: SLong, count: 1, value: int32(-2),
+			inline: [2]bool{true, true},
+			get:    func(e *Entry) (any, error) { return e.SLong() },
+			want:   int32(-2),
+		},
+		{
+			name: "LongSlice 0", typ: Long, count: 0,
+			inline: [2]bool{true, true},
+			get:    func(e *Entry) (any, error) { return e.LongSlice() },
+			want:   []uint32{},
+		},
+		{
+			name: "LongSlice 1", typ: Long, count: 1, value: []uint32{0xdeadbeef},
+			inline: [2]bool{true, true},
+			get:    func(e *Entry) (any, error) { return e.LongSlice() },
+			want:   []uint32{0xdeadbeef},
+		},
+		{
+			name: "LongSlice 2", typ: Long, count: 2, value: []uint32{1, 0xdeadbeef},
+			inline: [2]bool{false, true},
+			get:    func(e *Entry) (any, error) { return e.LongSlice() },
+			want:   []uint32{1, 0xdeadbeef},
+		},
+		{
+			name: "Rational", typ: Rational, count: 1, value: []uint32{1, 250},
+			inline: [2]bool{false, true},
+			get:    func(e *Entry) (any, error) { return e.Rational() },
+			want:   UnsignedRational{Numerator: 1, Denominator: 250},
+		},
+		{
+			name: "RationalSlice 1", typ: Rational, count: 1, value: []uint32{1, 250},
+			inline: [2]bool{false, true},
+			get:    func(e *Entry) (any, error) { return e.RationalSlice() },
+			want:   []UnsignedRational{{Numerator: 1, Denominator: 250}},
+		},
+		{
+			name: "SRational", typ: SRational, count: 1, value: []int32{-1, 3},
+			inline: [2]bool{false, true},
+			get:    func(e *Entry) (any, error) { return e.SRational() },
+			want:   SignedRational{Numerator: -1, Denominator: 3},
+		},
+		{
+			name: "Long8", typ: Long8, count: 1, value: uint64(0x0102030405060708),
+			inline: [2]bool{false, true},
+			get:    func(e *Entry) (any, error) { return e.Long8() },
+			want:   uint64(0x0102030405060708),
+		},
+		{
+			name: "Ascii", typ: Ascii, count: 4, value: []byte("abc\x00"),
+			inline: [2]bool{true, true},
+			get:    func(e *Entry) (any, error) { return e.Ascii() },
+			want:   "abc",
+		},
+	}
+
+	orders := []binary.ByteOrder{binary.LittleEndian, binary.BigEndian}
+
+	for _, order := range orders {
+		for i, big := range []bool{false, true} {
+			for _, c := range cases {
+				name := order.String() + "/" + c.name
+				if big {
+					name += "/BigTIFF"
+				}
+
+				t.Run(name, func(t *testing.T) {
+					tf, err := Parse(buildEntry(t, order, big, c.typ, c.count, c.value))
+					if err != nil {
+						t.Fatalf("Parse: %s", err)
+					}
+
+					e, err := tf.Entry(0, 0x1234)
+					if err != nil {
+						t.Fatalf("Entry: %s", err)
+					}
+
+					if inline := e.inline(c.typ.Size()); inline != c.inline[i] {
+						t.Errorf("inline is %t, expected %t", inline, c.inline[i])
+					}
+
+					got, err := c.get(&e)
+					if err != nil {
+						t.Fatalf("unexpected error: %s", err)
+					}
+
+					if !reflect.DeepEqual(got, c.want) {
+						t.Errorf("got %v, expected %v", got, c.want)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestEntryErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		typ  Type
+		get  func(e *Entry) (any, error)
+	}{
+		{"wrong type", Short, func(e *Entry) (any, error) { return e.Long() }},
+		{"not single", Long, func(e *Entry) (any, error) { return e.Long() }},
+		{"offset out of bounds", Long, func(e *Entry) (any, error) { return e.LongSlice() }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// Three values of any of the types don't fit in the value
+			// offset field, so it's set to point beyond the file.
+			tf, err := Parse(buildEntry(t, binary.LittleEndian, false, c.typ, 3, nil))
+			if err != nil {
+				t.Fatalf("Parse: %s", err)
+			}
+
+			e, err := tf.Entry(0, 0x1234)
+			if err != nil {
+				t.Fatalf("Entry: %s", err)
+			}
+
+			e.ValueOffset = [8]byte{0xf0, 0xff, 0xff, 0xff}
+
+			_, err = c.get(&e)
+			if err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
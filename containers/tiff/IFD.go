@@ -1,8 +1,17 @@
 package tiff
 
+import (
+	"errors"
+	"math"
+)
+
 // IFD is a type representing an Image File Directory in a TIFF file.
 type IFD []Entry
 
+// ErrNoImageData is returned if an IFD doesn't reference any image
+// data.
+var ErrNoImageData = errors.New("no image data")
+
 func (i IFD) Entry(tag Tag) (Entry, error) {
 	for _, entry := range i {
 		if Tag(entry.Tag) == tag {
@@ -12,3 +21,121 @@ func (i IFD) Entry(tag Tag) (Entry, error) {
 
 	return Entry{}, ErrTagNotFound
 }
+
+// uints returns the values of a Short, Long or Long8 entry. Long8
+// values not fitting an int64 are rejected.
+func uints(e Entry) ([]int64, error) {
+	var values []int64
+
+	switch e.Type {
+	case Short:
+		shorts, err := e.ShortSlice()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range shorts {
+			values = append(values, int64(v))
+		}
+
+	case Long8:
+		longs, err := e.Long8Slice()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range longs {
+			if v > math.MaxInt64 {
+				return nil, errors.New("value out of range")
+			}
+
+			values = append(values, int64(v))
+		}
+
+	default:
+		longs, err := e.LongSlice()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range longs {
+			values = append(values, int64(v))
+		}
+	}
+
+	return values, nil
+}
+
+// ImageData returns the image data referenced by the IFD. JPEG data
+// referenced by JPEGInterchangeFormat and JPEGInterchangeFormatLength
+// is preferred. If not present, the strips referenced by StripOffsets
+// and StripByteCounts are joined.
+func (i IFD) ImageData() ([]byte, error) {
+	offset, err := i.Entry(JPEGInterchangeFormat)
+	if err == nil {
+		length, err := i.Entry(JPEGInterchangeFormatLength)
+		if err != nil {
+			return nil, err
+		}
+
+		o, err := offset.Int()
+		if err != nil {
+			return nil, err
+		}
+
+		l, err := length.Int()
+		if err != nil {
+			return nil, err
+		}
+
+		return offset.tiff.read(int64(o), int64(l))
+	}
+
+	offsets, err := i.Entry(StripOffsets)
+	if err != nil {
+		return nil, ErrNoImageData
+	}
+
+	counts, err := i.Entry(StripByteCounts)
+	if err != nil {
+		return nil, ErrNoImageData
+	}
+
+	o, err := uints(offsets)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := uints(counts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(o) != len(c) {
+		return nil, errors.New("strip count mismatch")
+	}
+
+	size := offsets.tiff.r.Size()
+
+	var total int64
+	for _, count := range c {
+		if count < 0 || count > size-total {
+			return nil, errors.New("buffer too small")
+		}
+
+		total += count
+	}
+
+	data := make([]byte, 0, total)
+
+	for j := range o {
+		strip, err := offsets.tiff.read(o[j], c[j])
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, strip...)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,244 @@
+package tiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IFDNode is an IFD in the tree of IFDs of a TIFF file. The IFDs of
+// the main chain are named IFD0, IFD1 and so on. Their children are
+// the IFDs pointed to by their entries, named Exif, GPS, Interop and
+// SubIFD0, SubIFD1 and so on for the SubIFDs tag. The layout of a
+// MakerNote depends on the vendor, so it's not read while parsing.
+// The makernote package adds it to the Exif IFD as MakerNote when the
+// vendor stores an IFD. Unknown vendors are left out.
+type IFDNode struct {
+	Name string
+
+	// Offset is the offset of the IFD relative to the TIFF header it
+	// was read from.
+	Offset int64
+
+	Entries  IFD
+	Children []*IFDNode
+}
+
+// pointer is a tag pointing to child IFDs, and the name of the
+// children.
+type pointer struct {
+	tag  Tag
+	name string
+
+	// numbered is true if the tag can point to more than one IFD. The
+	// index of the IFD is appended to the name.
+	numbered bool
+}
+
+var pointers = []pointer{
+	{ExifIDFPointer, "Exif", false},
+	{GPSInfoIFDPointer, "GPS", false},
+	{InteroperabilityIFDPointer, "Interop", false},
+	{SubIFDs, "SubIFD", true},
+}
+
+// Child returns the child with the given name or nil.
+func (n *IFDNode) Child(name string) *IFDNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Tree returns the IFDs of the main chain with their children.
+func (t *Tiff) Tree() []*IFDNode {
+	return t.tree
+}
+
+// Graft adds the first IFD of child to the tree as a child of IFD0
+// with the given name, unless IFD0 has a child of that name already.
+// If t has no IFDs, an empty IFD0 is added to hold the child. The
+// nodes of t are copied before changing them, so trees shared with
+// other Tiffs are left untouched.
+func (t *Tiff) Graft(name string, child *Tiff) {
+	if child == nil || len(child.tree) == 0 {
+		return
+	}
+
+	root := &IFDNode{Name: "IFD0"}
+	if len(t.tree) > 0 {
+		if t.tree[0].Child(name) != nil {
+			return
+		}
+
+		copied := *t.tree[0]
+		root = &copied
+	}
+
+	node := *child.tree[0]
+	node.Name = name
+
+	root.Children = append(append([]*IFDNode{}, root.Children...), &node)
+
+	tree := []*IFDNode{root}
+	if len(t.tree) > 1 {
+		tree = append(tree, t.tree[1:]...)
+	}
+
+	t.tree = tree
+}
+
+// GraftAt adds the first IFD of child to the tree as a child of the
+// IFD at path with the given name, unless that IFD has a child of that
+// name already. If the IFD at path is not present, ErrIFDNotFound is
+// returned. Like Graft, the nodes along path are copied before
+// changing them.
+func (t *Tiff) GraftAt(path string, name string, child *Tiff) error {
+	if child == nil || len(child.tree) == 0 {
+		return nil
+	}
+
+	names := strings.Split(path, "/")
+
+	// index returns the index of the node with the given name.
+	index := func(nodes []*IFDNode, name string) int {
+		for i, n := range nodes {
+			if n.Name == name {
+				return i
+			}
+		}
+
+		return -1
+	}
+
+	tree := append([]*IFDNode{}, t.tree...)
+	nodes := tree
+
+	var parent *IFDNode
+
+	for _, name := range names {
+		i := index(nodes, name)
+		if i < 0 {
+			return ErrIFDNotFound
+		}
+
+		copied := *nodes[i]
+		nodes[i] = &copied
+
+		parent = &copied
+		parent.Children = append([]*IFDNode{}, parent.Children...)
+		nodes = parent.Children
+	}
+
+	if parent.Child(name) != nil {
+		return nil
+	}
+
+	node := *child.tree[0]
+	node.Name = name
+
+	parent.Children = append(parent.Children, &node)
+	t.tree = tree
+
+	return nil
+}
+
+// Node returns the IFD at the given path, like "IFD0/Exif/Interop"
+// or "IFD0/SubIFD1". If the IFD is not present, ErrIFDNotFound is
+// returned.
+func (t *Tiff) Node(path string) (*IFDNode, error) {
+	names := strings.Split(path, "/")
+
+	var node *IFDNode
+
+	for _, root := range t.tree {
+		if root.Name == names[0] {
+			node = root
+
+			break
+		}
+	}
+
+	for _, name := range names[1:] {
+		if node == nil {
+			break
+		}
+
+		node = node.Child(name)
+	}
+
+	if node == nil {
+		return nil, ErrIFDNotFound
+	}
+
+	return node, nil
+}
+
+// buildTree builds the tree from the IFDs of the main chain found at
+// the given offsets. IFDs that can't be read are left out, and each
+// offset is only visited once to guard against loops.
+func (t *Tiff) buildTree(offsets []int64) {
+	visited := make(map[int64]bool)
+
+	for _, offset := range offsets {
+		visited[offset] = true
+	}
+
+	t.tree = make([]*IFDNode, len(t.ifds))
+
+	for i, ifd := range t.ifds {
+		t.tree[i] = t.node(fmt.Sprintf("IFD%d", i), offsets[i], ifd, visited)
+	}
+}
+
+// node returns a node for ifd, reading its children recursively.
+func (t *Tiff) node(name string, offset int64, ifd IFD, visited map[int64]bool) *IFDNode {
+	n := &IFDNode{
+		Name:    name,
+		Offset:  offset,
+		Entries: ifd,
+	}
+
+	for _, p := range pointers {
+		entry, err := ifd.Entry(p.tag)
+		if err != nil {
+			continue
+		}
+
+		offsets, err := entry.IFDOffsets()
+		if err != nil {
+			continue
+		}
+
+		for i, offset := range offsets {
+			name := p.name
+			if p.numbered {
+				name = fmt.Sprintf("%s%d", name, i)
+			}
+
+			child, err := t.child(offset, visited)
+			if err != nil {
+				continue
+			}
+
+			n.Children = append(n.Children, t.node(name, offset, child, visited))
+		}
+	}
+
+	return n
+}
+
+// child reads the IFD at offset unless it has been visited already.
+func (t *Tiff) child(offset int64, visited map[int64]bool) (IFD, error) {
+	if visited[offset] {
+		return nil, fmt.Errorf("IFD at %d already read", offset)
+	}
+
+	visited[offset] = true
+
+	ifd, _, err := t.readIFD(offset)
+
+	return ifd, err
+}
@@ -0,0 +1,39 @@
+package tiff
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestGraftAt(t *testing.T) {
+	parse := func() *Tiff {
+		tf, err := Parse(buildEntry(t, binary.LittleEndian, false, Short, 1, uint16(1)))
+		if err != nil {
+			t.Fatalf("Parse: %s", err)
+		}
+
+		return tf
+	}
+
+	tf := parse()
+	tf.Graft("Exif", parse())
+
+	shared := tf.Tree()[0]
+
+	g := *tf
+	if err := g.GraftAt("IFD0/Exif", "MakerNote", parse()); err != nil {
+		t.Fatalf("GraftAt: %s", err)
+	}
+
+	if _, err := g.Node("IFD0/Exif/MakerNote"); err != nil {
+		t.Errorf("MakerNote not found: %s", err)
+	}
+
+	if len(shared.Child("Exif").Children) != 0 {
+		t.Errorf("the tree of the original Tiff was changed")
+	}
+
+	if err := g.GraftAt("IFD0/GPS", "MakerNote", parse()); err != ErrIFDNotFound {
+		t.Errorf("got %v for a missing IFD, expected ErrIFDNotFound", err)
+	}
+}
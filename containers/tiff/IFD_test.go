@@ -0,0 +1,67 @@
+package tiff
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// buildStrips returns a little endian BigTIFF file with a single strip
+// of the given Long8 offset and byte count.
+func buildStrips(offset uint64, count uint64) []byte {
+	le := binary.LittleEndian
+
+	data := make([]byte, 96)
+	copy(data, "II")
+	le.PutUint16(data[2:], bigMagic)
+	le.PutUint16(data[4:], 8)
+	le.PutUint64(data[8:], 16)
+	le.PutUint64(data[16:], 2)
+
+	entry := func(at int, tag Tag, value uint64) {
+		le.PutUint16(data[at:], uint16(tag))
+		le.PutUint16(data[at+2:], uint16(Long8))
+		le.PutUint64(data[at+4:], 1)
+		le.PutUint64(data[at+12:], value)
+	}
+
+	entry(24, StripOffsets, offset)
+	entry(44, StripByteCounts, count)
+
+	return data
+}
+
+func TestImageData(t *testing.T) {
+	tf, err := Parse(buildStrips(64, 32))
+	if err != nil {
+		t.Fatalf("Parse: %s", err)
+	}
+
+	data, err := tf.IFDs()[0].ImageData()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(data) != 32 {
+		t.Errorf("got %d bytes, expected 32", len(data))
+	}
+}
+
+func TestImageDataCountOutOfRange(t *testing.T) {
+	counts := []uint64{
+		0xffffffffffffffff,
+		0x7fffffffffffffff,
+		97,
+	}
+
+	for _, count := range counts {
+		tf, err := Parse(buildStrips(0, count))
+		if err != nil {
+			t.Fatalf("Parse: %s", err)
+		}
+
+		_, err = tf.IFDs()[0].ImageData()
+		if err == nil {
+			t.Errorf("%#x: expected an error", count)
+		}
+	}
+}
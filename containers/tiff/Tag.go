@@ -12,6 +12,7 @@ var _ fmt.Stringer = Tag(0)
 
 const (
 	// Tags related to image data structure.
+	NewSubfileType            Tag = 0x00FE
 	ImageWidth                Tag = 0x0100
 	ImageLength               Tag = 0x0101
 	BitsPerSample             Tag = 0x0102
@@ -32,6 +33,7 @@ const (
 	StripByteCounts             Tag = 0x0117
 	JPEGInterchangeFormat       Tag = 0x0201
 	JPEGInterchangeFormatLength Tag = 0x0202
+	SubIFDs                     Tag = 0x014A
 
 	// Tags related to image data characteristics.
 	TransferFunction      Tag = 0x012D
@@ -49,13 +51,25 @@ const (
 	Artist           Tag = 0x013B
 	Copyright        Tag = 0x8298
 
-	ExifIDFPointer    Tag = 0x8769
-	GPSInfoIFDPointer Tag = 0x8825
+	// Tags holding embedded metadata.
+	XMLPacket         Tag = 0x02BC
+	IPTCNAA           Tag = 0x83BB
+	ImageResources    Tag = 0x8649
+	InterColorProfile Tag = 0x8773
+
+	// Tags pointing to other IFDs. InteroperabilityIFDPointer and
+	// MakerNote are found in the Exif IFD. MakerNote points to vendor
+	// specific data, which is an IFD for most vendors.
+	ExifIDFPointer             Tag = 0x8769
+	GPSInfoIFDPointer          Tag = 0x8825
+	InteroperabilityIFDPointer Tag = 0xA005
+	MakerNote                  Tag = 0x927C
 )
 
 // String returns a string representation of the tag.
 func (t Tag) String() string {
 	m := map[Tag]string{
+		NewSubfileType:            "NewSubfileType",
 		ImageWidth:                "ImageWidth",
 		ImageLength:               "ImageLength",
 		BitsPerSample:             "BitsPerSample",
@@ -75,6 +89,7 @@ func (t Tag) String() string {
 		StripByteCounts:             "StripByteCounts",
 		JPEGInterchangeFormat:       "JPEGInterchangeFormat",
 		JPEGInterchangeFormatLength: "JPEGInterchangeFormatLength",
+		SubIFDs:                     "SubIFDs",
 
 		TransferFunction:      "TransferFunction",
 		WhitePoint:            "WhitePoint",
@@ -90,8 +105,15 @@ func (t Tag) String() string {
 		Artist:           "Artist",
 		Copyright:        "Copyright",
 
-		ExifIDFPointer:    "ExifIDFPointer",
-		GPSInfoIFDPointer: "GPSInfoIFDPointer",
+		XMLPacket:         "XMLPacket",
+		IPTCNAA:           "IPTCNAA",
+		ImageResources:    "ImageResources",
+		InterColorProfile: "InterColorProfile",
+
+		ExifIDFPointer:             "ExifIDFPointer",
+		GPSInfoIFDPointer:          "GPSInfoIFDPointer",
+		InteroperabilityIFDPointer: "InteroperabilityIFDPointer",
+		MakerNote:                  "MakerNote",
 	}
 
 	if s, ok := m[t]; ok {
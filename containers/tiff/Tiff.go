@@ -1,18 +1,33 @@
 package tiff
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
+	"io"
+	"math"
 )
 
 // Tiff is a type representing a TIFF file.
 type Tiff struct {
-	bytes []byte
+	r *io.SectionReader
 
 	endianness binary.ByteOrder
 	ifds       []IFD
+	tree       []*IFDNode
+
+	// big is true for BigTIFF files, using 8 byte offsets and counts.
+	big bool
 }
 
+const (
+	// magic identifies a classic TIFF file.
+	magic = 42
+
+	// bigMagic identifies a BigTIFF file.
+	bigMagic = 43
+)
+
 var (
 	// ErrNotTiff is returned if the data is not a TIFF file.
 	ErrNotTiff = errors.New("not a TIFF file")
@@ -26,79 +41,193 @@ var (
 
 // Parse parses the given data as a TIFF file or returns an error.
 func Parse(data []byte) (*Tiff, error) {
-	if len(data) < 8 {
+	return ParseReaderAt(bytes.NewReader(data), int64(len(data)))
+}
+
+// ParseReaderAt parses size bytes from r as a TIFF file or returns
+// an error. Only the IFDs are read while parsing, entry values are
+// read from r when requested.
+func ParseReaderAt(r io.ReaderAt, size int64) (*Tiff, error) {
+	if size < 8 {
 		return nil, ErrNotTiff
 	}
 
-	var endianness binary.ByteOrder
+	t := &Tiff{
+		r: io.NewSectionReader(r, 0, size),
+	}
+
+	header, err := t.read(0, 8)
+	if err != nil {
+		return nil, ErrNotTiff
+	}
 
 	switch {
-	case data[0] == 'I' && data[1] == 'I':
-		endianness = binary.LittleEndian
+	case header[0] == 'I' && header[1] == 'I':
+		t.endianness = binary.LittleEndian
 
-	case data[0] == 'M' && data[1] == 'M':
-		endianness = binary.BigEndian
+	case header[0] == 'M' && header[1] == 'M':
+		t.endianness = binary.BigEndian
 
 	default:
 		return nil, ErrNotTiff
 	}
 
-	magic := endianness.Uint16(data[2:])
-	if magic != 42 {
-		return nil, ErrNotTiff
-	}
+	var ifdOffset int64
 
-	t := &Tiff{
-		bytes:      data,
-		endianness: endianness,
-	}
+	switch t.endianness.Uint16(header[2:]) {
+	case magic:
+		// Get the offset to the first IFD
+		ifdOffset = int64(t.endianness.Uint32(header[4:]))
+
+	case bigMagic:
+		t.big = true
+
+		// BigTIFF has the offset size, always 8, and a reserved
+		// zero before the offset to the first IFD.
+		header, err = t.read(0, 16)
+		if err != nil {
+			return nil, ErrNotTiff
+		}
+
+		if t.endianness.Uint16(header[4:]) != 8 || t.endianness.Uint16(header[6:]) != 0 {
+			return nil, ErrNotTiff
+		}
 
-	// Get the offset to the first IFD
-	ifdOffset := int(endianness.Uint32(data[4:]))
+		ifdOffset = int64(t.endianness.Uint64(header[8:]))
+
+	default:
+		return nil, ErrNotTiff
+	}
 
 	// Check ifdOffset
-	if ifdOffset < 8 || ifdOffset >= len(data)-2 {
+	if ifdOffset < 8 || ifdOffset >= size-t.countSize() {
 		return nil, errors.New("IFD offset out of bounds")
 	}
 
 	// Read the IFDs
 	t.ifds = []IFD{}
+
+	var offsets []int64
+
 	for {
-		ifd, err := t.ReadIFD(ifdOffset)
+		ifd, nextIfdOffset, err := t.readIFD(ifdOffset)
 		if err != nil {
 			return nil, err
 		}
 
-		ifdCount := int(endianness.Uint16(data[ifdOffset:]))
-		if ifdCount < 1 {
+		if len(ifd) < 1 {
 			break
 		}
 
-		// Validate ifdCount
-		if len(data) < ifdOffset+2+ifdCount*12+4 {
-			return nil, errors.New("IFD count mismatch")
-		}
-
 		t.ifds = append(t.ifds, ifd)
-
-		// Check if there is another IFD
-		if len(data) < int(ifdOffset)+2 {
-			return nil, errors.New("Buffer too small")
-		}
-
-		nextIfdOffset := int(endianness.Uint32(data[ifdOffset+2+ifdCount*12:]))
+		offsets = append(offsets, ifdOffset)
 
 		// Chec if next IFD offset seems legit.
-		if nextIfdOffset < ifdOffset+2+ifdCount*12+4 {
+		if nextIfdOffset < ifdOffset+t.ifdSize(int64(len(ifd))) {
 			break
 		}
 
 		ifdOffset = nextIfdOffset
 	}
 
+	t.buildTree(offsets)
+
 	return t, nil
 }
 
+// ParseIFD parses a lone IFD at offset in size bytes of r, without a
+// TIFF header, as found in MakerNotes. Offsets in the IFD are relative
+// to the start of r. The IFD is returned as IFD0 of the Tiff, and the
+// offset to a next IFD is ignored.
+func ParseIFD(r io.ReaderAt, size int64, order binary.ByteOrder, offset int64) (*Tiff, error) {
+	t := &Tiff{
+		r:          io.NewSectionReader(r, 0, size),
+		endianness: order,
+	}
+
+	ifd, _, err := t.readIFD(offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ifd) < 1 {
+		return nil, ErrIFDNotFound
+	}
+
+	t.ifds = []IFD{ifd}
+	t.buildTree([]int64{offset})
+
+	return t, nil
+}
+
+// countSize returns the size of the entry count of an IFD.
+func (t *Tiff) countSize() int64 {
+	if t.big {
+		return 8
+	}
+
+	return 2
+}
+
+// entrySize returns the size of an IFD entry.
+func (t *Tiff) entrySize() int64 {
+	if t.big {
+		return 20
+	}
+
+	return 12
+}
+
+// valueSize returns the size of the value offset field of an entry,
+// and the size of the offset to the next IFD.
+func (t *Tiff) valueSize() int64 {
+	if t.big {
+		return 8
+	}
+
+	return 4
+}
+
+// ifdSize returns the size of an IFD with count entries, including
+// the offset to the next IFD.
+func (t *Tiff) ifdSize(count int64) int64 {
+	return t.countSize() + count*t.entrySize() + t.valueSize()
+}
+
+// ByteOrder returns the byte order of the file.
+func (t *Tiff) ByteOrder() binary.ByteOrder {
+	return t.endianness
+}
+
+// Reader returns the reader the TIFF data is read from. Offsets in
+// the file are relative to the start of the reader.
+func (t *Tiff) Reader() *io.SectionReader {
+	return t.r
+}
+
+// Big returns true if the file is a BigTIFF.
+func (t *Tiff) Big() bool {
+	return t.big
+}
+
+// read reads length bytes at the given offset relative to the start
+// of the TIFF header. Nothing is allocated if the range extends beyond
+// the end of the reader.
+func (t *Tiff) read(offset int64, length int64) ([]byte, error) {
+	if offset < 0 || length < 0 || offset > t.r.Size() || length > t.r.Size()-offset {
+		return nil, errors.New("buffer too small")
+	}
+
+	buf := make([]byte, length)
+
+	n, err := t.r.ReadAt(buf, offset)
+	if n == len(buf) {
+		return buf, nil
+	}
+
+	return nil, err
+}
+
 // IFDs returns the IFDs in the TIFF file.
 func (t *Tiff) IFDs() []IFD {
 	return t.ifds
@@ -133,45 +262,81 @@ func (t *Tiff) Entry(ifd int, tag Tag) (Entry, error) {
 
 // ReadIFD reads the IFD at the given offset.
 func (t *Tiff) ReadIFD(offset int) (IFD, error) {
-	if offset < 0 || offset >= len(t.bytes) {
-		return nil, errors.New("offset out of bounds")
-	}
+	ifd, _, err := t.readIFD(int64(offset))
 
-	buf := t.bytes[offset:]
+	return ifd, err
+}
 
-	if len(buf) < 2 {
-		return nil, errors.New("buffer too small")
+// readIFD reads the IFD at the given offset and returns it along with
+// the offset of the next IFD.
+func (t *Tiff) readIFD(offset int64) (IFD, int64, error) {
+	if offset < 0 || offset >= t.r.Size() {
+		return nil, 0, errors.New("offset out of bounds")
+	}
+
+	buf, err := t.read(offset, t.countSize())
+	if err != nil {
+		return nil, 0, err
 	}
 
 	// Get number of IFDs
-	ifdCount := t.endianness.Uint16(buf[0:2])
+	var ifdCount int64
+	if t.big {
+		ifdCount = int64(t.endianness.Uint64(buf))
+	} else {
+		ifdCount = int64(t.endianness.Uint16(buf))
+	}
 
-	if len(t.bytes) < int(ifdCount)*12+2 {
-		return nil, errors.New("buffer too small")
+	if ifdCount < 0 || ifdCount > (t.r.Size()-offset)/t.entrySize() {
+		return nil, 0, errors.New("buffer too small")
 	}
 
-	// Check if IFD count would take more space than the buffer
-	if len(buf) < int(ifdCount)*12+2 {
-		return nil, errors.New("buffer too small")
+	// Check if IFD count would take more space than the buffer. The
+	// offset to the next IFD is optional at the end of the file.
+	buf, err = t.read(offset+t.countSize(), ifdCount*t.entrySize())
+	if err != nil {
+		return nil, 0, err
 	}
 
 	// Read the IFDs
 	ifds := make(IFD, ifdCount)
 
-	for i := 0; i < int(ifdCount); i++ {
+	for i := range ifds {
+		b := buf[int64(i)*t.entrySize():]
+
 		ifd := Entry{
-			Tag:         Tag(t.endianness.Uint16(buf[i*12+2 : i*12+4])),
-			Type:        Type(t.endianness.Uint16(buf[i*12+4 : i*12+6])),
-			Count:       t.endianness.Uint32(buf[i*12+6 : i*12+10]),
-			ValueOffset: *(*[4]byte)(buf[i*12+10 : i*12+14]),
+			Tag:  Tag(t.endianness.Uint16(b[0:2])),
+			Type: Type(t.endianness.Uint16(b[2:4])),
 
 			tiff: t,
 		}
 
+		if t.big {
+			count := t.endianness.Uint64(b[4:12])
+			if count > math.MaxUint32 {
+				return nil, 0, errors.New("entry count too large")
+			}
+
+			ifd.Count = uint32(count)
+			copy(ifd.ValueOffset[:], b[12:20])
+		} else {
+			ifd.Count = t.endianness.Uint32(b[4:8])
+			copy(ifd.ValueOffset[:], b[8:12])
+		}
+
 		ifds[i] = ifd
 	}
 
-	return ifds, nil
+	next, err := t.read(offset+t.countSize()+ifdCount*t.entrySize(), t.valueSize())
+	if err != nil {
+		return ifds, 0, nil
+	}
+
+	if t.big {
+		return ifds, int64(t.endianness.Uint64(next)), nil
+	}
+
+	return ifds, int64(t.endianness.Uint32(next)), nil
 }
 
 // Ascii returns the ASCII value of a tag. AnyIFD can be passed to
@@ -13,9 +13,22 @@ const (
 	Short     Type = 3
 	Long      Type = 4
 	Rational  Type = 5
+	SByte     Type = 6
 	Undefined Type = 7
+	SShort    Type = 8
 	SLong     Type = 9
 	SRational Type = 10
+	Float     Type = 11
+	Double    Type = 12
+
+	// IFDType is a Long holding the offset of an IFD. It's named with
+	// a suffix to avoid clashing with the IFD type.
+	IFDType Type = 13
+
+	// BigTIFF types.
+	Long8    Type = 16
+	SLong8   Type = 17
+	IFD8Type Type = 18
 )
 
 var _ fmt.Stringer = Type(0)
@@ -33,13 +46,46 @@ func (t Type) String() string {
 		return "Long"
 	case Rational:
 		return "Rational"
+	case SByte:
+		return "SByte"
 	case Undefined:
 		return "Undefined"
+	case SShort:
+		return "SShort"
 	case SLong:
 		return "SLong"
 	case SRational:
 		return "SRational"
+	case Float:
+		return "Float"
+	case Double:
+		return "Double"
+	case IFDType:
+		return "IFD"
+	case Long8:
+		return "Long8"
+	case SLong8:
+		return "SLong8"
+	case IFD8Type:
+		return "IFD8"
 	}
 
 	return "Unknown"
 }
+
+// Size returns the size in bytes of a single value of the type, or 0
+// for unknown types.
+func (t Type) Size() int64 {
+	switch t {
+	case Byte, Ascii, SByte, Undefined:
+		return 1
+	case Short, SShort:
+		return 2
+	case Long, SLong, Float, IFDType:
+		return 4
+	case Rational, SRational, Double, Long8, SLong8, IFD8Type:
+		return 8
+	}
+
+	return 0
+}
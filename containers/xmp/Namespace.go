@@ -0,0 +1,25 @@
+package xmp
+
+// Namespace URIs for commonly used XMP schemas. Properties are looked
+// up by namespace URI, not by prefix, as prefixes are chosen freely by
+// the writer.
+const (
+	NsRDF         = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	NsXML         = "http://www.w3.org/XML/1998/namespace"
+	NsDC          = "http://purl.org/dc/elements/1.1/"
+	NsXMP         = "http://ns.adobe.com/xap/1.0/"
+	NsXMPRights   = "http://ns.adobe.com/xap/1.0/rights/"
+	NsXMPMM       = "http://ns.adobe.com/xap/1.0/mm/"
+	NsXMPNote     = "http://ns.adobe.com/xmp/note/"
+	NsPhotoshop   = "http://ns.adobe.com/photoshop/1.0/"
+	NsLightroom   = "http://ns.adobe.com/lightroom/1.0/"
+	NsCameraRaw   = "http://ns.adobe.com/camera-raw-settings/1.0/"
+	NsExif        = "http://ns.adobe.com/exif/1.0/"
+	NsExifEX      = "http://cipa.jp/exif/1.0/"
+	NsExifAux     = "http://ns.adobe.com/exif/1.0/aux/"
+	NsTIFF        = "http://ns.adobe.com/tiff/1.0/"
+	NsIPTCCore    = "http://iptc.org/std/Iptc4xmpCore/1.0/xmlns/"
+	NsIPTCExt     = "http://iptc.org/std/Iptc4xmpExt/2008-02-29/"
+	NsGCamera     = "http://ns.google.com/photos/1.0/camera/"
+	NsAppleCamera = "http://ns.apple.com/camera/1.0/"
+)
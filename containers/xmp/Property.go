@@ -0,0 +1,158 @@
+package xmp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the kind of an XMP property value.
+type Kind uint8
+
+const (
+	Simple Kind = iota
+	Struct
+	Bag
+	Seq
+	Alt
+)
+
+var _ fmt.Stringer = Kind(0)
+
+// String returns a string representation of the kind.
+func (k Kind) String() string {
+	switch k {
+	case Simple:
+		return "Simple"
+	case Struct:
+		return "Struct"
+	case Bag:
+		return "Bag"
+	case Seq:
+		return "Seq"
+	case Alt:
+		return "Alt"
+	default:
+		return "Unknown"
+	}
+}
+
+// DefaultLang is the language of the default item in a language
+// alternative.
+const DefaultLang = "x-default"
+
+// Property is a node in the XMP tree.
+type Property struct {
+	Namespace string
+	Name      string
+	Kind      Kind
+
+	// Value is the value of a simple property.
+	Value string
+
+	// Lang is the xml:lang qualifier, if any.
+	Lang string
+
+	// Items are the items of a Bag, Seq or Alt array. Items are named
+	// "li" in the RDF namespace.
+	Items []*Property
+
+	// Fields are the fields of a struct.
+	Fields []*Property
+
+	// Qualifiers are the qualifiers of a simple value other than
+	// xml:lang, given using rdf:value.
+	Qualifiers []*Property
+}
+
+// IsArray returns true if the property is a Bag, Seq or Alt.
+func (p *Property) IsArray() bool {
+	return p.Kind == Bag || p.Kind == Seq || p.Kind == Alt
+}
+
+// Field returns the struct field with the given namespace and name or
+// ErrPropertyNotFound.
+func (p *Property) Field(namespace string, name string) (*Property, error) {
+	for _, f := range p.Fields {
+		if f.Namespace == namespace && f.Name == name {
+			return f, nil
+		}
+	}
+
+	return nil, ErrPropertyNotFound
+}
+
+// Qualifier returns the qualifier with the given namespace and name or
+// ErrPropertyNotFound.
+func (p *Property) Qualifier(namespace string, name string) (*Property, error) {
+	for _, q := range p.Qualifiers {
+		if q.Namespace == namespace && q.Name == name {
+			return q, nil
+		}
+	}
+
+	return nil, ErrPropertyNotFound
+}
+
+// Text returns the value of a simple property. For language
+// alternatives the default language is returned.
+func (p *Property) Text() (string, error) {
+	switch p.Kind {
+	case Simple:
+		return p.Value, nil
+
+	case Alt:
+		return p.LangText(DefaultLang)
+
+	default:
+		return "", ErrUnexpectedKind
+	}
+}
+
+// LangText returns the item of a language alternative matching lang.
+// If no item matches, the default item is returned, and lacking that,
+// the first item.
+func (p *Property) LangText(lang string) (string, error) {
+	if p.Kind == Simple {
+		return p.Value, nil
+	}
+
+	if p.Kind != Alt {
+		return "", ErrUnexpectedKind
+	}
+
+	if len(p.Items) == 0 {
+		return "", ErrPropertyNotFound
+	}
+
+	for _, want := range []string{lang, DefaultLang} {
+		for _, item := range p.Items {
+			if item.Kind == Simple && strings.EqualFold(item.Lang, want) {
+				return item.Value, nil
+			}
+		}
+	}
+
+	return p.Items[0].Value, nil
+}
+
+// Strings returns the values of the simple items of an array. A simple
+// property is returned as a single value.
+func (p *Property) Strings() ([]string, error) {
+	if p.Kind == Simple {
+		return []string{p.Value}, nil
+	}
+
+	if !p.IsArray() {
+		return nil, ErrUnexpectedKind
+	}
+
+	values := make([]string, 0, len(p.Items))
+
+	for _, item := range p.Items {
+		if item.Kind == Simple {
+			values = append(values, item.Value)
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,244 @@
+package xmp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// maxDepth is the deepest element nesting accepted. The RDF is
+// interpreted recursively, and real packets are only a few levels deep.
+const maxDepth = 256
+
+// element is a generic XML element. RDF interpretation is done on
+// the complete tree.
+type element struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*element
+	text     strings.Builder
+}
+
+// attr returns the value of the attribute with the given name.
+func (e *element) attr(space string, local string) (string, bool) {
+	for _, a := range e.attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// find returns the first element named name in a depth first search.
+func (e *element) find(name xml.Name) *element {
+	if e.name == name {
+		return e
+	}
+
+	for _, c := range e.children {
+		if found := c.find(name); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// readElements reads data into a tree of elements.
+func readElements(data []byte) (*element, error) {
+	// Skip anything before the first tag, like a byte order mark.
+	start := bytes.IndexByte(data, '<')
+	if start < 0 {
+		return nil, ErrMalformed
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(data[start:]))
+
+	root := &element{}
+	stack := []*element{root}
+
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, ErrMalformed
+		}
+
+		top := stack[len(stack)-1]
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(stack) > maxDepth {
+				return nil, ErrMalformed
+			}
+
+			e := &element{name: t.Name, attrs: t.Attr}
+			top.children = append(top.children, e)
+			stack = append(stack, e)
+
+		case xml.EndElement:
+			if len(stack) < 2 {
+				return nil, ErrMalformed
+			}
+
+			stack = stack[:len(stack)-1]
+
+		case xml.CharData:
+			top.text.Write(t)
+		}
+	}
+
+	return root, nil
+}
+
+// isSyntaxAttr returns true for attributes that are part of the
+// RDF/XML syntax rather than properties.
+func isSyntaxAttr(a xml.Attr) bool {
+	switch a.Name.Space {
+	case NsRDF, NsXML, "xmlns":
+		return true
+
+	case "":
+		return a.Name.Local == "xmlns"
+	}
+
+	return false
+}
+
+// attrProperties returns the properties given in attribute form.
+func attrProperties(e *element) []*Property {
+	var properties []*Property
+
+	for _, a := range e.attrs {
+		if isSyntaxAttr(a) {
+			continue
+		}
+
+		properties = append(properties, &Property{
+			Namespace: a.Name.Space,
+			Name:      a.Name.Local,
+			Kind:      Simple,
+			Value:     a.Value,
+		})
+	}
+
+	return properties
+}
+
+// nodeProperties returns the properties of a node element, like
+// rdf:Description.
+func nodeProperties(e *element) []*Property {
+	properties := attrProperties(e)
+
+	for _, c := range e.children {
+		properties = append(properties, parseProperty(c))
+	}
+
+	return properties
+}
+
+var arrayKinds = map[string]Kind{
+	"Bag": Bag,
+	"Seq": Seq,
+	"Alt": Alt,
+}
+
+// parseProperty parses a property element and its value.
+func parseProperty(e *element) *Property {
+	p := &Property{
+		Namespace: e.name.Space,
+		Name:      e.name.Local,
+	}
+
+	p.Lang, _ = e.attr(NsXML, "lang")
+
+	if resource, found := e.attr(NsRDF, "resource"); found {
+		p.Kind = Simple
+		p.Value = resource
+
+		return p
+	}
+
+	if parseType, _ := e.attr(NsRDF, "parseType"); parseType == "Resource" {
+		p.Kind = Struct
+		p.Fields = nodeProperties(e)
+
+		return unwrapValue(p)
+	}
+
+	if len(e.children) == 0 {
+		fields := attrProperties(e)
+		if len(fields) > 0 {
+			p.Kind = Struct
+			p.Fields = fields
+
+			return unwrapValue(p)
+		}
+
+		p.Kind = Simple
+		p.Value = e.text.String()
+
+		return p
+	}
+
+	c := e.children[0]
+
+	if kind, found := arrayKinds[c.name.Local]; found && c.name.Space == NsRDF {
+		p.Kind = kind
+
+		for _, li := range c.children {
+			if li.name.Space == NsRDF && li.name.Local == "li" {
+				p.Items = append(p.Items, parseProperty(li))
+			}
+		}
+
+		return p
+	}
+
+	p.Kind = Struct
+
+	if c.name.Space == NsRDF && c.name.Local == "Description" {
+		p.Fields = nodeProperties(c)
+	} else {
+		// Not valid RDF, but keep the children as fields rather
+		// than dropping them.
+		for _, c := range e.children {
+			p.Fields = append(p.Fields, parseProperty(c))
+		}
+	}
+
+	return unwrapValue(p)
+}
+
+// unwrapValue turns a struct with an rdf:value field into a simple
+// value with the remaining fields as qualifiers.
+func unwrapValue(p *Property) *Property {
+	value, err := p.Field(NsRDF, "value")
+	if err != nil {
+		return p
+	}
+
+	for _, f := range p.Fields {
+		if f == value {
+			continue
+		}
+
+		p.Qualifiers = append(p.Qualifiers, f)
+	}
+
+	if p.Lang == "" {
+		p.Lang = value.Lang
+	}
+
+	p.Kind = value.Kind
+	p.Value = value.Value
+	p.Items = value.Items
+	p.Fields = value.Fields
+
+	return p
+}
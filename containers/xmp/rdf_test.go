@@ -0,0 +1,36 @@
+package xmp
+
+import (
+	"strings"
+	"testing"
+)
+
+// packet wraps properties in an rdf:Description.
+func packet(properties string) string {
+	return `<x:xmpmeta xmlns:x="adobe:ns:meta/">` +
+		`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+		`<rdf:Description xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+		properties +
+		`</rdf:Description></rdf:RDF></x:xmpmeta>`
+}
+
+func TestParseNested(t *testing.T) {
+	cases := []struct {
+		name  string
+		depth int
+		want  error
+	}{
+		{"shallow", 10, nil},
+		{"deep", 100000, ErrMalformed},
+	}
+
+	for _, c := range cases {
+		start := strings.Repeat(`<rdf:Description><dc:f>`, c.depth)
+		end := strings.Repeat(`</dc:f></rdf:Description>`, c.depth)
+
+		_, err := Parse([]byte(packet(`<dc:title>` + start + end + `</dc:title>`)))
+		if err != c.want {
+			t.Errorf("%s: got %v, expected %v", c.name, err, c.want)
+		}
+	}
+}
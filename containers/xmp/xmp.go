@@ -0,0 +1,240 @@
+package xmp
+
+import (
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoXMPFound is returned if no XMP packet is found.
+	ErrNoXMPFound = errors.New("no XMP data found")
+
+	// ErrMalformed is returned if the packet is not well formed XML or
+	// lacks an rdf:RDF element.
+	ErrMalformed = errors.New("malformed XMP packet")
+
+	// ErrPropertyNotFound is returned if a property is not present.
+	ErrPropertyNotFound = errors.New("property not found")
+
+	// ErrUnexpectedKind is returned if a property is not of the kind
+	// expected by the getter, like asking for the text of a struct.
+	ErrUnexpectedKind = errors.New("unexpected property kind")
+)
+
+// XMP is a parsed XMP packet.
+type XMP struct {
+	// Properties are the top level properties of all rdf:Description
+	// elements in the packet.
+	Properties []*Property
+}
+
+// Parse parses an XMP packet, usually as returned by XMP() on a file
+// type.
+func Parse(data []byte) (*XMP, error) {
+	root, err := readElements(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rdf := root.find(xml.Name{Space: NsRDF, Local: "RDF"})
+	if rdf == nil {
+		return nil, ErrMalformed
+	}
+
+	x := &XMP{}
+
+	for _, e := range rdf.children {
+		if e.name.Space == NsRDF && e.name.Local == "Description" {
+			x.Properties = append(x.Properties, nodeProperties(e)...)
+		}
+	}
+
+	return x, nil
+}
+
+// Property returns the top level property with the given namespace and
+// name or ErrPropertyNotFound.
+func (x *XMP) Property(namespace string, name string) (*Property, error) {
+	for _, p := range x.Properties {
+		if p.Namespace == namespace && p.Name == name {
+			return p, nil
+		}
+	}
+
+	return nil, ErrPropertyNotFound
+}
+
+// Text returns the value of a simple property. For language
+// alternatives the default language is returned.
+func (x *XMP) Text(namespace string, name string) (string, error) {
+	p, err := x.Property(namespace, name)
+	if err != nil {
+		return "", err
+	}
+
+	return p.Text()
+}
+
+// LangText returns the value of a language alternative in the given
+// language. See Property.LangText.
+func (x *XMP) LangText(namespace string, name string, lang string) (string, error) {
+	p, err := x.Property(namespace, name)
+	if err != nil {
+		return "", err
+	}
+
+	return p.LangText(lang)
+}
+
+// Strings returns the values of an array property.
+func (x *XMP) Strings(namespace string, name string) ([]string, error) {
+	p, err := x.Property(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Strings()
+}
+
+// Int returns the value of a simple property as an integer.
+func (x *XMP) Int(namespace string, name string) (int, error) {
+	str, err := x.Text(namespace, name)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(str))
+}
+
+// Float returns the value of a simple property as a float. Rationals
+// like "1/200", as used by the exif and tiff namespaces, are
+// supported.
+func (x *XMP) Float(namespace string, name string) (float64, error) {
+	str, err := x.Text(namespace, name)
+	if err != nil {
+		return 0, err
+	}
+
+	return parseFloat(strings.TrimSpace(str))
+}
+
+func parseFloat(str string) (float64, error) {
+	num, den, found := strings.Cut(str, "/")
+	if !found {
+		return strconv.ParseFloat(str, 64)
+	}
+
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if d == 0 {
+		return 0, strconv.ErrRange
+	}
+
+	return n / d, nil
+}
+
+// Time returns the value of a date property. XMP dates can be partial
+// and may lack a timezone, in which case loc is used.
+func (x *XMP) Time(namespace string, name string, loc *time.Location) (time.Time, error) {
+	str, err := x.Text(namespace, name)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return parseTime(strings.TrimSpace(str), loc)
+}
+
+// timeLayouts are the date formats allowed by XMP, from most to least
+// precise. Fractional seconds are accepted by time.Parse without being
+// part of the layout.
+var timeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+func parseTime(str string, loc *time.Location) (time.Time, error) {
+	var err error
+
+	for _, layout := range timeLayouts {
+		var t time.Time
+
+		t, err = time.Parse(layout+"Z07:00", str)
+		if err == nil {
+			return t, nil
+		}
+
+		t, err = time.ParseInLocation(layout, str, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+// Title returns the default dc:title.
+func (x *XMP) Title() (string, error) {
+	return x.Text(NsDC, "title")
+}
+
+// Description returns the default dc:description.
+func (x *XMP) Description() (string, error) {
+	return x.Text(NsDC, "description")
+}
+
+// Creators returns the dc:creator list.
+func (x *XMP) Creators() ([]string, error) {
+	return x.Strings(NsDC, "creator")
+}
+
+// Subjects returns the dc:subject keywords.
+func (x *XMP) Subjects() ([]string, error) {
+	return x.Strings(NsDC, "subject")
+}
+
+// Rating returns xmp:Rating. -1 means rejected, 0 unrated and 1 to 5
+// the number of stars.
+func (x *XMP) Rating() (int, error) {
+	f, err := x.Float(NsXMP, "Rating")
+	if err != nil {
+		return 0, err
+	}
+
+	return int(f), nil
+}
+
+// CreateDate returns xmp:CreateDate.
+func (x *XMP) CreateDate(loc *time.Location) (time.Time, error) {
+	return x.Time(NsXMP, "CreateDate", loc)
+}
+
+// ModifyDate returns xmp:ModifyDate.
+func (x *XMP) ModifyDate(loc *time.Location) (time.Time, error) {
+	return x.Time(NsXMP, "ModifyDate", loc)
+}
+
+// DateCreated returns photoshop:DateCreated, the date the content was
+// created rather than the date the file was.
+func (x *XMP) DateCreated(loc *time.Location) (time.Time, error) {
+	return x.Time(NsPhotoshop, "DateCreated", loc)
+}
+
+// HierarchicalSubjects returns lr:hierarchicalSubject, keywords with
+// levels separated by "|", like "Places|Denmark|Copenhagen".
+func (x *XMP) HierarchicalSubjects() ([]string, error) {
+	return x.Strings(NsLightroom, "hierarchicalSubject")
+}
@@ -2,6 +2,7 @@ package fileformats
 
 import (
 	"errors"
+	"io"
 
 	"github.com/abrander/apexif/containers/exif"
 )
@@ -12,6 +13,14 @@ type FileType interface {
 	// found, nil and ErrNoExifFound is returned.
 	Exif() (*exif.Exif, error)
 
+	// XMP returns the raw XMP packet from the file if found. If not
+	// found, nil and xmp.ErrNoXMPFound is returned.
+	XMP() ([]byte, error)
+
+	// ICCProfile returns the raw ICC profile from the file if found.
+	// If not found, nil and icc.ErrNoICCFound is returned.
+	ICCProfile() ([]byte, error)
+
 	// Name returns the name of the file format.
 	Name() string
 
@@ -25,6 +34,11 @@ type FileType interface {
 // This should be implemented by all file formats.
 type Identifier func(data []byte) (FileType, error)
 
+// ReaderAtIdentifier is like Identifier, but reads size bytes from an
+// io.ReaderAt instead of requiring the whole file in memory. Only the
+// parts needed to identify the file are read.
+type ReaderAtIdentifier func(r io.ReaderAt, size int64) (FileType, error)
+
 // ErrImageNotRecognized is returned by the Identify function if
 // the file format is not recognized.
 var ErrImageNotRecognized = errors.New("image file format not recognized")
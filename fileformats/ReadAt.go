@@ -0,0 +1,23 @@
+package fileformats
+
+import (
+	"io"
+)
+
+// ReadAt reads length bytes at offset from r. If the read would extend
+// beyond the end of r, io.ErrUnexpectedEOF is returned before anything
+// is allocated.
+func ReadAt(r *io.SectionReader, offset int64, length int64) ([]byte, error) {
+	if offset < 0 || length < 0 || offset > r.Size() || length > r.Size()-offset {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	buf := make([]byte, length)
+
+	n, err := r.ReadAt(buf, offset)
+	if n == len(buf) {
+		return buf, nil
+	}
+
+	return nil, err
+}
@@ -0,0 +1,117 @@
+package avif
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/abrander/apexif/containers/bmff"
+	"github.com/abrander/apexif/containers/exif"
+	"github.com/abrander/apexif/containers/icc"
+	"github.com/abrander/apexif/containers/xmp"
+	"github.com/abrander/apexif/fileformats"
+)
+
+type AVIF struct {
+	r *io.SectionReader
+
+	bmff *bmff.Bmff
+}
+
+var _ fileformats.FileType = &AVIF{}
+
+func Identify(data []byte) (fileformats.FileType, error) {
+	return IdentifyReaderAt(bytes.NewReader(data), int64(len(data)))
+}
+
+func IdentifyReaderAt(r io.ReaderAt, size int64) (fileformats.FileType, error) {
+	// Check that the file looks like an AVIF file.
+	if size < 12 {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	sr := io.NewSectionReader(r, 0, size)
+
+	ftyp, err := bmff.ReadFtyp(sr)
+	if err != nil {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	if !ftyp.HasBrand("avif") && !ftyp.HasBrand("avis") {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	return &AVIF{
+		r: sr,
+	}, nil
+}
+
+func (a *AVIF) Name() string {
+	return "AVIF"
+}
+
+func (a *AVIF) MediaType() string {
+	return "image/avif"
+}
+
+// container returns the parsed container, parsing it on first use.
+func (a *AVIF) container() (*bmff.Bmff, error) {
+	if a.bmff != nil {
+		return a.bmff, nil
+	}
+
+	b, err := bmff.ParseReaderAt(a.r, a.r.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	a.bmff = b
+
+	return b, nil
+}
+
+func (a *AVIF) Exif() (*exif.Exif, error) {
+	b, err := a.container()
+	if err != nil {
+		return nil, err
+	}
+
+	exifBytes, err := b.Exif()
+	if errors.Is(err, bmff.ErrItemNotFound) {
+		return nil, exif.ErrNoExifFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return exif.Parse(exifBytes)
+}
+
+func (a *AVIF) XMP() ([]byte, error) {
+	b, err := a.container()
+	if err != nil {
+		return nil, xmp.ErrNoXMPFound
+	}
+
+	packet, err := b.XMP()
+	if errors.Is(err, bmff.ErrItemNotFound) {
+		return nil, xmp.ErrNoXMPFound
+	}
+
+	return packet, err
+}
+
+func (a *AVIF) ICCProfile() ([]byte, error) {
+	b, err := a.container()
+	if err != nil {
+		return nil, icc.ErrNoICCFound
+	}
+
+	profile, err := b.ICCProfile()
+	if err != nil {
+		return nil, icc.ErrNoICCFound
+	}
+
+	return profile, nil
+}
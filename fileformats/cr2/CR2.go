@@ -1,12 +1,18 @@
 package cr2
 
 import (
+	"bytes"
+	"io"
+
 	"github.com/abrander/apexif/containers/exif"
+	"github.com/abrander/apexif/containers/icc"
+	"github.com/abrander/apexif/containers/tiff"
+	"github.com/abrander/apexif/containers/xmp"
 	"github.com/abrander/apexif/fileformats"
 )
 
 type CR2 struct {
-	bytes []byte
+	r *io.SectionReader
 }
 
 const signature = "II*\x00\x10\x00"
@@ -14,12 +20,23 @@ const signature = "II*\x00\x10\x00"
 var _ fileformats.FileType = &CR2{}
 
 func Identify(buf []byte) (fileformats.FileType, error) {
-	if len(buf) < 10*1024 || string(buf[0:6]) != signature {
+	return IdentifyReaderAt(bytes.NewReader(buf), int64(len(buf)))
+}
+
+func IdentifyReaderAt(r io.ReaderAt, size int64) (fileformats.FileType, error) {
+	if size < 10*1024 {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	sr := io.NewSectionReader(r, 0, size)
+
+	header, err := fileformats.ReadAt(sr, 0, int64(len(signature)))
+	if err != nil || string(header) != signature {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
 	return &CR2{
-		bytes: buf,
+		r: sr,
 	}, nil
 }
 
@@ -32,5 +49,43 @@ func (c *CR2) MediaType() string {
 }
 
 func (c *CR2) Exif() (*exif.Exif, error) {
-	return exif.Parse(c.bytes)
+	return exif.ParseReaderAt(c.r, c.r.Size())
+}
+
+func (c *CR2) XMP() ([]byte, error) {
+	e, err := c.Exif()
+	if err != nil {
+		return nil, xmp.ErrNoXMPFound
+	}
+
+	return e.XMP()
+}
+
+func (c *CR2) ICCProfile() ([]byte, error) {
+	e, err := c.Exif()
+	if err != nil {
+		return nil, icc.ErrNoICCFound
+	}
+
+	return e.ICCProfile()
+}
+
+// Preview returns the full size JPEG preview referenced by IFD0.
+func (c *CR2) Preview() ([]byte, error) {
+	e, err := c.Exif()
+	if err != nil {
+		return nil, err
+	}
+
+	ifds := e.IFDs()
+	if len(ifds) < 1 {
+		return nil, exif.ErrNoPreview
+	}
+
+	data, err := ifds[0].ImageData()
+	if err == tiff.ErrNoImageData {
+		return nil, exif.ErrNoPreview
+	}
+
+	return data, err
 }
@@ -0,0 +1,156 @@
+package cr3
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/abrander/apexif/containers/bmff"
+	"github.com/abrander/apexif/containers/exif"
+	"github.com/abrander/apexif/containers/icc"
+	"github.com/abrander/apexif/containers/tiff"
+	"github.com/abrander/apexif/containers/xmp"
+	"github.com/abrander/apexif/fileformats"
+)
+
+type CR3 struct {
+	r *io.SectionReader
+}
+
+const (
+	// canonUUID is the user type of the uuid box in moov holding the
+	// Canon metadata boxes.
+	canonUUID = "\x85\xc0\xb6\x87\x82\x0f\x11\xe0\x81\x11\xf4\xce\x46\x2b\x6a\x48"
+
+	// xmpUUID is the user type of the top level uuid box holding the
+	// XMP packet.
+	xmpUUID = "\xbe\x7a\xcf\xcb\x97\xa9\x42\xe8\x9c\x71\x99\x94\x91\xe3\xaf\xac"
+)
+
+var _ fileformats.FileType = &CR3{}
+
+func Identify(data []byte) (fileformats.FileType, error) {
+	return IdentifyReaderAt(bytes.NewReader(data), int64(len(data)))
+}
+
+func IdentifyReaderAt(r io.ReaderAt, size int64) (fileformats.FileType, error) {
+	if size < 12 {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	sr := io.NewSectionReader(r, 0, size)
+
+	header, err := fileformats.ReadAt(sr, 0, 12)
+	if err != nil {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	if string(header[4:12]) != "ftypcrx " {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	return &CR3{
+		r: sr,
+	}, nil
+}
+
+func (c *CR3) Name() string {
+	return "CR3"
+}
+
+func (c *CR3) MediaType() string {
+	return "image/x-canon-cr3"
+}
+
+// metadata returns the CMT1 to CMT4 boxes found in the Canon uuid
+// box in moov.
+func (c *CR3) metadata() (map[string]*bmff.Box, error) {
+	b, err := bmff.ParseReaderAt(c.r, c.r.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	moov, err := b.Find("moov")
+	if err != nil {
+		return nil, exif.ErrNoExifFound
+	}
+
+	for _, uuid := range moov.Children {
+		if uuid.Type != "uuid" || uuid.UserType != canonUUID {
+			continue
+		}
+
+		children, err := uuid.ReadChildren()
+		if err != nil {
+			return nil, err
+		}
+
+		cmt := make(map[string]*bmff.Box)
+
+		for _, box := range children {
+			switch box.Type {
+			case "CMT1", "CMT2", "CMT3", "CMT4":
+				cmt[box.Type] = box
+			}
+		}
+
+		return cmt, nil
+	}
+
+	return nil, exif.ErrNoExifFound
+}
+
+// parseCMT parses the CMT box of the given type as a TIFF structure.
+// If the box is not present nil is returned.
+func (c *CR3) parseCMT(cmt map[string]*bmff.Box, boxType string) (*tiff.Tiff, error) {
+	box, found := cmt[boxType]
+	if !found {
+		return nil, nil
+	}
+
+	return tiff.ParseReaderAt(io.NewSectionReader(c.r, box.PayloadOffset(), box.PayloadSize()), box.PayloadSize())
+}
+
+func (c *CR3) Exif() (*exif.Exif, error) {
+	cmt, err := c.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	ifd0, err := c.parseCMT(cmt, "CMT1")
+	if err != nil {
+		return nil, err
+	}
+
+	if ifd0 == nil {
+		return nil, exif.ErrNoExifFound
+	}
+
+	// The remaining boxes are optional, and a broken one should not
+	// prevent access to the rest.
+	exifIFD, _ := c.parseCMT(cmt, "CMT2")
+	makerNote, _ := c.parseCMT(cmt, "CMT3")
+	gps, _ := c.parseCMT(cmt, "CMT4")
+
+	return exif.Assemble(ifd0, exifIFD, gps, makerNote), nil
+}
+
+func (c *CR3) XMP() ([]byte, error) {
+	b, err := bmff.ParseReaderAt(c.r, c.r.Size())
+	if err != nil {
+		return nil, xmp.ErrNoXMPFound
+	}
+
+	for _, box := range b.Boxes() {
+		if box.Type == "uuid" && box.UserType == xmpUUID {
+			return box.Payload()
+		}
+	}
+
+	return nil, xmp.ErrNoXMPFound
+}
+
+// ICCProfile always returns icc.ErrNoICCFound, CR3 files carry no ICC
+// profile.
+func (c *CR3) ICCProfile() ([]byte, error) {
+	return nil, icc.ErrNoICCFound
+}
@@ -1,33 +1,49 @@
 package crw
 
 import (
+	"bytes"
 	"encoding/binary"
 	"io"
+	"strings"
 
 	"github.com/abrander/apexif/containers/exif"
+	"github.com/abrander/apexif/containers/icc"
+	"github.com/abrander/apexif/containers/makernote"
+	"github.com/abrander/apexif/containers/xmp"
 	"github.com/abrander/apexif/fileformats"
 )
 
 type CRW struct {
-	bytes []byte
+	r *io.SectionReader
 }
 
 var _ fileformats.FileType = &CRW{}
 
-func Identify(bytes []byte) (fileformats.FileType, error) {
-	if len(bytes) < 10 {
+func Identify(data []byte) (fileformats.FileType, error) {
+	return IdentifyReaderAt(bytes.NewReader(data), int64(len(data)))
+}
+
+func IdentifyReaderAt(r io.ReaderAt, size int64) (fileformats.FileType, error) {
+	if size < 14 {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	sr := io.NewSectionReader(r, 0, size)
+
+	header, err := fileformats.ReadAt(sr, 0, 14)
+	if err != nil {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
-	if bytes[0] != 'I' || bytes[1] != 'I' {
+	if header[0] != 'I' || header[1] != 'I' {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
-	if string(bytes[6:14]) != "HEAPCCDR" {
+	if string(header[6:14]) != "HEAPCCDR" {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
-	return &CRW{bytes: bytes}, nil
+	return &CRW{r: sr}, nil
 }
 
 func (c *CRW) Name() string {
@@ -38,16 +54,23 @@ func (c *CRW) MediaType() string {
 	return "image/x-canon-crw"
 }
 
-func (c *CRW) Exif() (*exif.Exif, error) {
-	root := binary.LittleEndian.Uint32(c.bytes[2:6])
+// root returns the root heap, following the header.
+func (c *CRW) root() (*heap, error) {
+	header, err := fileformats.ReadAt(c.r, 2, 4)
+	if err != nil {
+		return nil, err
+	}
 
-	offset := binary.LittleEndian.Uint32(c.bytes[len(c.bytes)-4:]) + root
-	if offset > uint32(len(c.bytes)) {
+	root := int64(binary.LittleEndian.Uint32(header))
+	if root > c.r.Size() {
 		return nil, io.ErrUnexpectedEOF
 	}
 
-	data := c.bytes[root:]
-	heap, err := readHeap(data)
+	return readHeap(io.NewSectionReader(c.r, root, c.r.Size()-root))
+}
+
+func (c *CRW) Exif() (*exif.Exif, error) {
+	heap, err := c.root()
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +80,11 @@ func (c *CRW) Exif() (*exif.Exif, error) {
 		return nil, err
 	}
 
-	data = data[imageprops.Offset : imageprops.Offset+imageprops.Length]
+	data, err := heap.section(imageprops)
+	if err != nil {
+		return nil, err
+	}
+
 	props, err := readHeap(data)
 	if err != nil {
 		return nil, err
@@ -68,8 +95,96 @@ func (c *CRW) Exif() (*exif.Exif, error) {
 		return nil, err
 	}
 
-	data = data[buh.Offset : buh.Offset+buh.Length]
+	data, err = props.section(buh)
+	if err != nil {
+		return nil, err
+	}
+
 	_, err = readHeap(data)
 
 	return nil, err
 }
+
+// XMP always returns xmp.ErrNoXMPFound, CRW files carry no XMP.
+func (c *CRW) XMP() ([]byte, error) {
+	return nil, xmp.ErrNoXMPFound
+}
+
+// ICCProfile always returns icc.ErrNoICCFound, CRW files carry no ICC
+// profile.
+func (c *CRW) ICCProfile() ([]byte, error) {
+	return nil, icc.ErrNoICCFound
+}
+
+// image returns the data of the record of type t in the root heap.
+func (c *CRW) image(t Type, notFound error) ([]byte, error) {
+	heap, err := c.root()
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := heap.findType(t)
+	if err != nil {
+		return nil, notFound
+	}
+
+	return heap.Bytes(record)
+}
+
+// Preview returns the JpgFromRaw record, a JPEG preview in the full
+// image size.
+func (c *CRW) Preview() ([]byte, error) {
+	return c.image(JpgFromRaw, exif.ErrNoPreview)
+}
+
+// Thumbnail returns the ThumbnailImage record, a small JPEG.
+func (c *CRW) Thumbnail() ([]byte, error) {
+	return c.image(ThumbnailImage, exif.ErrNoThumbnail)
+}
+
+// Canon returns the Canon specific records, decoded the same way as
+// the Canon MakerNote of other formats.
+func (c *CRW) Canon() (*makernote.Canon, error) {
+	heap, err := c.root()
+	if err != nil {
+		return nil, err
+	}
+
+	canon := &makernote.Canon{}
+
+	// The make and model are stored as two strings.
+	var model string
+	if data, err := heap.deepBytes(kTC_ModelName); err == nil {
+		parts := strings.Split(string(data), "\000")
+		if len(parts) > 1 {
+			model = parts[1]
+		}
+	}
+
+	canon.ImageType, _ = heap.ascii(kTC_CanonImageType)
+	canon.FirmwareVersion, _ = heap.ascii(kTC_FirmwareVersion)
+	canon.OwnerName, _ = heap.ascii(kTC_OwnerName)
+	canon.ModelID, _ = heap.dword(CanonModelID)
+
+	if serial, err := heap.dword(kTC_SerialNumber); err == nil {
+		canon.SerialNumber = makernote.CanonSerialNumber(model, serial)
+	}
+
+	if v, err := heap.words(CanonCameraSettings); err == nil {
+		canon.CameraSettings = makernote.ParseCanonCameraSettings(v)
+	}
+
+	if v, err := heap.words(CanonShotInfo); err == nil {
+		canon.ShotInfo = makernote.ParseCanonShotInfo(v)
+	}
+
+	if v, err := heap.words(CanonFileInfo); err == nil {
+		canon.FileInfo = makernote.ParseCanonFileInfo(v, model)
+	}
+
+	if v, err := heap.words(CanonAFInfo); err == nil {
+		canon.AFInfo, _ = makernote.ParseCanonAFInfo(v)
+	}
+
+	return canon, nil
+}
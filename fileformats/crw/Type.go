@@ -36,6 +36,7 @@ const (
 	kTC_ComponentVersion  Type = kDT_ASCII | 0x000c
 	kTC_ROMOperationMode  Type = kDT_ASCII | 0x000d
 	kTC_OwnerName         Type = kDT_ASCII | 0x0010
+	kTC_CanonImageType    Type = kDT_ASCII | 0x0015
 	kTC_ImageFileName     Type = kDT_ASCII | 0x0016
 	kTC_ThumbnailFileName Type = kDT_ASCII | 0x0017
 
@@ -123,6 +124,8 @@ func (t Type) String() string {
 		ret += "ROMOperationMode"
 	case kTC_OwnerName:
 		ret += "OwnerName"
+	case kTC_CanonImageType:
+		ret += "CanonImageType"
 	case kTC_ImageFileName:
 		ret += "ImageFileName"
 	case kTC_ThumbnailFileName:
@@ -11,6 +11,10 @@ type dataRecord struct {
 	Type   Type
 	Offset uint32
 	Length uint32
+
+	// inRecord is true if the data is stored in the record itself
+	// instead of the heap space.
+	inRecord bool
 }
 
 func (r dataRecord) String() string {
@@ -31,6 +35,7 @@ func readDataRecord(data []byte) (dataRecord, error) {
 
 	if (dr.Type & kStgFormatMask) == kStg_InRecordEntry {
 		dr.Offset = 2
+		dr.inRecord = true
 	}
 
 	dr.Type &= 0x3fff
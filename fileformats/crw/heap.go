@@ -1,13 +1,16 @@
 package crw
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/abrander/apexif/fileformats"
 )
 
 type heap struct {
-	bytes   []byte
+	r       *io.SectionReader
 	records []dataRecord
 }
 
@@ -25,31 +28,197 @@ func (h *heap) find(tag Type) (dataRecord, error) {
 	return dataRecord{}, errTagNotFound
 }
 
+// findType returns the first record of the given type, including the
+// data type bits.
+func (h *heap) findType(t Type) (dataRecord, error) {
+	for _, r := range h.records {
+		if r.Type&kTypeIDCodeMask == t {
+			return r, nil
+		}
+	}
+
+	return dataRecord{}, errTagNotFound
+}
+
+// maxDepth is the maximum depth of nested heaps searched by
+// findDeep, and maxHeaps the maximum number of heaps read by a single
+// search. Real files have about a dozen heaps.
+const (
+	maxDepth = 8
+	maxHeaps = 256
+)
+
+// span is the position of a heap relative to the heap a search
+// started from.
+type span struct {
+	offset int64
+	length int64
+}
+
+// search holds the state of a findDeep search. Each heap is only read
+// once, as a record can point to its own heap or one of its parents.
+type search struct {
+	t       Type
+	visited map[span]bool
+}
+
+// findDeep returns the first record of the given type, including the
+// data type bits, in the heap or the heaps below it, along with the
+// heap holding it.
+func (h *heap) findDeep(t Type) (*heap, dataRecord, error) {
+	s := &search{
+		t:       t,
+		visited: map[span]bool{{0, h.r.Size()}: true},
+	}
+
+	return s.find(h, 0, 0)
+}
+
+// find searches h, located at offset, and the heaps below it.
+func (s *search) find(h *heap, offset int64, depth int) (*heap, dataRecord, error) {
+	if r, err := h.findType(s.t); err == nil {
+		return h, r, nil
+	}
+
+	if depth >= maxDepth {
+		return nil, dataRecord{}, errTagNotFound
+	}
+
+	for _, r := range h.records {
+		dataType := r.Type & kDataTypeMask
+		if r.inRecord || (dataType != kDT_HeapTypeProperty1 && dataType != kDT_HeapTypeProperty2) {
+			continue
+		}
+
+		sp := span{offset + int64(r.Offset), int64(r.Length)}
+		if s.visited[sp] || len(s.visited) >= maxHeaps {
+			continue
+		}
+
+		s.visited[sp] = true
+
+		section, err := h.section(r)
+		if err != nil {
+			continue
+		}
+
+		sub, err := readHeap(section)
+		if err != nil {
+			continue
+		}
+
+		if found, record, err := s.find(sub, sp.offset, depth+1); err == nil {
+			return found, record, nil
+		}
+	}
+
+	return nil, dataRecord{}, errTagNotFound
+}
+
+// deepBytes returns the data of the first record of the given type in
+// the heap or the heaps below it.
+func (h *heap) deepBytes(t Type) ([]byte, error) {
+	found, record, err := h.findDeep(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return found.Bytes(record)
+}
+
+// ascii returns the first string of the given type in the heap or the
+// heaps below it.
+func (h *heap) ascii(t Type) (string, error) {
+	data, err := h.deepBytes(t)
+	if err != nil {
+		return "", err
+	}
+
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+
+	return string(data), nil
+}
+
+// dword returns the first 32 bit value of the given type in the heap
+// or the heaps below it.
+func (h *heap) dword(t Type) (uint32, error) {
+	data, err := h.deepBytes(t)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(data) < 4 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	return binary.LittleEndian.Uint32(data), nil
+}
+
+// words returns the data of the given type in the heap or the heaps
+// below it as 16 bit words.
+func (h *heap) words(t Type) ([]uint16, error) {
+	data, err := h.deepBytes(t)
+	if err != nil {
+		return nil, err
+	}
+
+	v := make([]uint16, len(data)/2)
+	for i := range v {
+		v[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+
+	return v, nil
+}
+
 func (h *heap) Bytes(record dataRecord) ([]byte, error) {
-	if record.Type&kStgFormatMask == kStg_InRecordEntry {
+	if record.inRecord {
 		return record.bytes[2:], nil
 	}
 
-	if record.Offset+record.Length > uint32(len(h.bytes)) {
+	return fileformats.ReadAt(h.r, int64(record.Offset), int64(record.Length))
+}
+
+// section returns a reader for the data of a record stored in the
+// heap space.
+func (h *heap) section(record dataRecord) (*io.SectionReader, error) {
+	if int64(record.Offset)+int64(record.Length) > h.r.Size() {
 		return nil, io.ErrUnexpectedEOF
 	}
 
-	return h.bytes[record.Offset : record.Offset+record.Length], nil
+	return io.NewSectionReader(h.r, int64(record.Offset), int64(record.Length)), nil
 }
 
-func readHeap(data []byte) (*heap, error) {
-	offsetTblOffset := binary.LittleEndian.Uint32(data[len(data)-4:])
-	records := binary.LittleEndian.Uint16(data[offsetTblOffset:])
+func readHeap(r *io.SectionReader) (*heap, error) {
+	buf, err := fileformats.ReadAt(r, r.Size()-4, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	offsetTblOffset := int64(binary.LittleEndian.Uint32(buf))
+
+	buf, err = fileformats.ReadAt(r, offsetTblOffset, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	records := binary.LittleEndian.Uint16(buf)
+
+	table, err := fileformats.ReadAt(r, offsetTblOffset+2, 10*int64(records))
+	if err != nil {
+		return nil, err
+	}
 
 	h := &heap{
-		bytes:   data,
+		r:       r,
 		records: make([]dataRecord, records),
 	}
 
 	for r := uint16(0); r < records; r++ {
-		offset := offsetTblOffset + 2 + (10 * uint32(r))
+		offset := 10 * uint32(r)
 
-		record, err := readDataRecord(data[offset : offset+10])
+		record, err := readDataRecord(table[offset : offset+10])
 		if err != nil {
 			return nil, err
 		}
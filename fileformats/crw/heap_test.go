@@ -0,0 +1,85 @@
+package crw
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+)
+
+// buildHeap returns a heap holding data followed by the record table.
+func buildHeap(data []byte, records ...dataRecord) []byte {
+	var b bytes.Buffer
+
+	w := func(v any) {
+		_ = binary.Write(&b, binary.LittleEndian, v)
+	}
+
+	b.Write(data)
+	w(uint16(len(records)))
+
+	for _, r := range records {
+		w(uint16(r.Type))
+		w(r.Length)
+		w(r.Offset)
+	}
+
+	w(uint32(len(data)))
+
+	return b.Bytes()
+}
+
+func parseHeap(t *testing.T, data []byte) *heap {
+	t.Helper()
+
+	h, err := readHeap(io.NewSectionReader(bytes.NewReader(data), 0, int64(len(data))))
+	if err != nil {
+		t.Fatalf("readHeap: %s", err)
+	}
+
+	return h
+}
+
+func TestFindDeep(t *testing.T) {
+	sub := buildHeap([]byte("Canon\x00"), dataRecord{Type: kTC_OwnerName, Length: 6})
+	data := buildHeap(sub, dataRecord{Type: kTC_CameraObject, Length: uint32(len(sub))})
+
+	str, err := parseHeap(t, data).ascii(kTC_OwnerName)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if str != "Canon" {
+		t.Errorf("got %q, expected Canon", str)
+	}
+}
+
+func TestFindDeepSelfReference(t *testing.T) {
+	// Eight sub-heap records all covering the heap holding them.
+	const size = 2 + 8*10 + 4
+
+	var records []dataRecord
+	for i := 0; i < 8; i++ {
+		records = append(records, dataRecord{Type: kDT_HeapTypeProperty1 | Type(i), Length: size})
+	}
+
+	h := parseHeap(t, buildHeap(nil, records...))
+
+	done := make(chan error)
+
+	go func() {
+		_, err := h.ascii(kTC_OwnerName)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != errTagNotFound {
+			t.Errorf("got %v, expected errTagNotFound", err)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatalf("search did not finish")
+	}
+}
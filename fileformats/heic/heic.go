@@ -1,47 +1,399 @@
 package heic
 
 import (
+	"bytes"
+	"errors"
+	"io"
+
 	"github.com/abrander/apexif/containers/bmff"
 	"github.com/abrander/apexif/containers/exif"
+	"github.com/abrander/apexif/containers/icc"
+	"github.com/abrander/apexif/containers/xmp"
 	"github.com/abrander/apexif/fileformats"
 )
 
 type HEIC struct {
-	bytes []byte
+	r *io.SectionReader
+
+	mediaType string
+	bmff      *bmff.Bmff
 }
 
 var _ fileformats.FileType = &HEIC{}
 
+var (
+	// ErrNoDimensions is returned if the primary image has no ispe
+	// property.
+	ErrNoDimensions = errors.New("no image dimensions found")
+
+	// ErrNoThumbnail is returned if the primary image has no HEVC
+	// thumbnail.
+	ErrNoThumbnail = errors.New("no thumbnail found")
+
+	// ErrNoDepthMap is returned if the primary image has no depth map.
+	ErrNoDepthMap = errors.New("no depth map found")
+)
+
+const (
+	mediaTypeHEIC         = "image/heic"
+	mediaTypeHEICSequence = "image/heic-sequence"
+	mediaTypeHEIF         = "image/heif"
+	mediaTypeHEIFSequence = "image/heif-sequence"
+)
+
+// brands lists the HEIF brands we recognize in order of preference
+// and the media type they imply.
+var brands = []struct {
+	brand     string
+	mediaType string
+}{
+	{"heic", mediaTypeHEIC},
+	{"heix", mediaTypeHEIC},
+	{"heim", mediaTypeHEIC},
+	{"heis", mediaTypeHEIC},
+	{"hevc", mediaTypeHEICSequence},
+	{"hevx", mediaTypeHEICSequence},
+	{"hevm", mediaTypeHEICSequence},
+	{"hevs", mediaTypeHEICSequence},
+	{"mif1", mediaTypeHEIF},
+	{"msf1", mediaTypeHEIFSequence},
+}
+
+// refinements maps the generic HEIF media types to their HEVC
+// specific counterparts.
+var refinements = map[string]string{
+	mediaTypeHEIF:         mediaTypeHEIC,
+	mediaTypeHEIFSequence: mediaTypeHEICSequence,
+}
+
+// mediaType returns the media type implied by the brands in ftyp, or
+// an empty string if ftyp carries no HEIF brand. The major brand
+// decides between image and sequence, while the compatible brands
+// can refine a generic HEIF brand to HEIC.
+func mediaType(ftyp bmff.Ftyp) string {
+	var major string
+
+	for _, b := range brands {
+		if b.brand == ftyp.MajorBrand {
+			major = b.mediaType
+		}
+	}
+
+	for _, b := range brands {
+		if !ftyp.HasBrand(b.brand) {
+			continue
+		}
+
+		switch {
+		case major == "":
+			return b.mediaType
+
+		case major == b.mediaType, refinements[major] == b.mediaType:
+			return b.mediaType
+		}
+	}
+
+	return major
+}
+
 func Identify(data []byte) (fileformats.FileType, error) {
+	return IdentifyReaderAt(bytes.NewReader(data), int64(len(data)))
+}
+
+func IdentifyReaderAt(r io.ReaderAt, size int64) (fileformats.FileType, error) {
 	// Check that the file looks like a HEIC file.
-	if len(data) < 12 {
+	if size < 12 {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	sr := io.NewSectionReader(r, 0, size)
+
+	ftyp, err := bmff.ReadFtyp(sr)
+	if err != nil {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
-	if string(data[4:12]) != "ftypheic" {
+	// AVIF is built on HEIF too, and will usually list mif1 as a
+	// compatible brand.
+	if ftyp.HasBrand("avif") || ftyp.HasBrand("avis") {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	mediaType := mediaType(ftyp)
+	if mediaType == "" {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
 	return &HEIC{
-		bytes: data,
+		r:         sr,
+		mediaType: mediaType,
 	}, nil
 }
 
 func (h *HEIC) Name() string {
+	switch h.mediaType {
+	case mediaTypeHEIF, mediaTypeHEIFSequence:
+		return "HEIF"
+	}
+
 	return "HEIC"
 }
 
 func (h *HEIC) MediaType() string {
-	return "image/heic"
+	return h.mediaType
+}
+
+// container returns the parsed container, parsing it on first use.
+func (h *HEIC) container() (*bmff.Bmff, error) {
+	if h.bmff != nil {
+		return h.bmff, nil
+	}
+
+	b, err := bmff.ParseReaderAt(h.r, h.r.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	h.bmff = b
+
+	return b, nil
 }
 
 func (h *HEIC) Exif() (*exif.Exif, error) {
-	b, err := bmff.Parse(h.bytes)
+	b, err := h.container()
 	if err != nil {
 		return nil, err
 	}
 
-	exifBytes := b.Iloc("Exif")
+	exifBytes, err := b.Exif()
+	if errors.Is(err, bmff.ErrItemNotFound) {
+		return nil, exif.ErrNoExifFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
 
 	return exif.Parse(exifBytes)
 }
+
+func (h *HEIC) XMP() ([]byte, error) {
+	b, err := h.container()
+	if err != nil {
+		return nil, xmp.ErrNoXMPFound
+	}
+
+	packet, err := b.XMP()
+	if errors.Is(err, bmff.ErrItemNotFound) {
+		return nil, xmp.ErrNoXMPFound
+	}
+
+	return packet, err
+}
+
+func (h *HEIC) ICCProfile() ([]byte, error) {
+	b, err := h.container()
+	if err != nil {
+		return nil, icc.ErrNoICCFound
+	}
+
+	profile, err := b.ICCProfile()
+	if err != nil {
+		return nil, icc.ErrNoICCFound
+	}
+
+	return profile, nil
+}
+
+// primaryProperties returns the properties of the primary image.
+func (h *HEIC) primaryProperties() (bmff.ItemProperties, error) {
+	b, err := h.container()
+	if err != nil {
+		return bmff.ItemProperties{}, err
+	}
+
+	primary, err := b.PrimaryItem()
+	if err != nil {
+		return bmff.ItemProperties{}, err
+	}
+
+	return b.ItemProperties(primary)
+}
+
+// Dimensions returns the width and height of the primary image as
+// encoded, before any rotation or mirroring is applied.
+func (h *HEIC) Dimensions() (int, int, error) {
+	props, err := h.primaryProperties()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if props.Extents == nil {
+		return 0, 0, ErrNoDimensions
+	}
+
+	return int(props.Extents.Width), int(props.Extents.Height), nil
+}
+
+// Rotation returns the rotation in degrees anti-clockwise to apply to
+// the primary image when displaying it. If the image carries no
+// rotation, 0 is returned.
+func (h *HEIC) Rotation() (int, error) {
+	props, err := h.primaryProperties()
+	if err != nil {
+		return 0, err
+	}
+
+	if props.Rotation == nil {
+		return 0, nil
+	}
+
+	return props.Rotation.Angle, nil
+}
+
+// Mirror returns the mirroring to apply to the primary image when
+// displaying it. If the image is not to be mirrored, nil is returned.
+// Use MirrorFirst to find out if it comes before or after the
+// rotation.
+func (h *HEIC) Mirror() (*bmff.ImageMirror, error) {
+	props, err := h.primaryProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	return props.Mirror, nil
+}
+
+// MirrorFirst returns true if the mirroring is to be applied before
+// the rotation. HEIF applies the transformations in the order they are
+// associated with the image.
+func (h *HEIC) MirrorFirst() (bool, error) {
+	props, err := h.primaryProperties()
+	if err != nil {
+		return false, err
+	}
+
+	return props.MirrorFirst, nil
+}
+
+// Thumbnail returns the coded data of the first HEVC thumbnail of the
+// primary image. The data is a raw HEVC bitstream, decoding it
+// requires the decoder configuration from the hvcC property.
+func (h *HEIC) Thumbnail() ([]byte, error) {
+	b, err := h.container()
+	if err != nil {
+		return nil, err
+	}
+
+	primary, err := b.PrimaryItem()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range b.ReferencingItems(primary, "thmb") {
+		item, err := b.Item(id)
+		if err != nil || item.Type != "hvc1" {
+			continue
+		}
+
+		return b.ItemData(id)
+	}
+
+	return nil, ErrNoThumbnail
+}
+
+// AuxiliaryImage is a type describing an auxiliary image of the
+// primary image, like an alpha plane or a depth map.
+type AuxiliaryImage struct {
+	ItemID uint32
+
+	// Type is the item type, usually "hvc1".
+	Type string
+
+	// URN identifies the kind of auxiliary image.
+	URN string
+
+	Width  int
+	Height int
+}
+
+// IsDepth returns true if the auxiliary image is a depth map.
+func (a AuxiliaryImage) IsDepth() bool {
+	switch a.URN {
+	case "urn:mpeg:hevc:2015:auxid:2", "urn:mpeg:mpegB:cicp:systems:auxiliary:depth":
+		return true
+	}
+
+	return false
+}
+
+// IsAlpha returns true if the auxiliary image is an alpha plane.
+func (a AuxiliaryImage) IsAlpha() bool {
+	switch a.URN {
+	case "urn:mpeg:hevc:2015:auxid:1", "urn:mpeg:mpegB:cicp:systems:auxiliary:alpha":
+		return true
+	}
+
+	return false
+}
+
+// AuxiliaryImages returns the auxiliary images of the primary image.
+func (h *HEIC) AuxiliaryImages() ([]AuxiliaryImage, error) {
+	b, err := h.container()
+	if err != nil {
+		return nil, err
+	}
+
+	primary, err := b.PrimaryItem()
+	if err != nil {
+		return nil, err
+	}
+
+	var images []AuxiliaryImage
+
+	for _, id := range b.ReferencingItems(primary, "auxl") {
+		item, err := b.Item(id)
+		if err != nil {
+			continue
+		}
+
+		props, err := b.ItemProperties(id)
+		if err != nil {
+			continue
+		}
+
+		image := AuxiliaryImage{
+			ItemID: id,
+			Type:   item.Type,
+		}
+
+		if props.Auxiliary != nil {
+			image.URN = props.Auxiliary.URN
+		}
+
+		if props.Extents != nil {
+			image.Width = int(props.Extents.Width)
+			image.Height = int(props.Extents.Height)
+		}
+
+		images = append(images, image)
+	}
+
+	return images, nil
+}
+
+// DepthMap returns the first depth map of the primary image.
+func (h *HEIC) DepthMap() (AuxiliaryImage, error) {
+	images, err := h.AuxiliaryImages()
+	if err != nil {
+		return AuxiliaryImage{}, err
+	}
+
+	for _, image := range images {
+		if image.IsDepth() {
+			return image, nil
+		}
+	}
+
+	return AuxiliaryImage{}, ErrNoDepthMap
+}
@@ -1,30 +1,52 @@
 package jpeg
 
 import (
+	"bytes"
 	"encoding/binary"
+	"io"
+	"regexp"
 
 	"github.com/abrander/apexif/containers/exif"
+	"github.com/abrander/apexif/containers/icc"
+	"github.com/abrander/apexif/containers/iptc"
+	"github.com/abrander/apexif/containers/psirb"
+	"github.com/abrander/apexif/containers/xmp"
 	"github.com/abrander/apexif/fileformats"
 )
 
 type JPEG struct {
-	bytes []byte
+	r *io.SectionReader
 }
 
 const (
-	SOI  = 0xffd8 // Start of image
-	APP1 = 0xffe1 // Exif (mostly)
+	SOI   = 0xffd8 // Start of image
+	APP1  = 0xffe1 // Exif (mostly)
+	APP2  = 0xffe2 // ICC profile
+	APP13 = 0xffed // Photoshop image resources
+	SOS   = 0xffda // Start of scan
+	EOI   = 0xffd9 // End of image
 )
 
 var _ fileformats.FileType = &JPEG{}
 
 func Identify(data []byte) (fileformats.FileType, error) {
-	if len(data) < 16 || binary.BigEndian.Uint16(data) != SOI || data[2] != 0xff {
+	return IdentifyReaderAt(bytes.NewReader(data), int64(len(data)))
+}
+
+func IdentifyReaderAt(r io.ReaderAt, size int64) (fileformats.FileType, error) {
+	if size < 16 {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	sr := io.NewSectionReader(r, 0, size)
+
+	header, err := fileformats.ReadAt(sr, 0, 3)
+	if err != nil || binary.BigEndian.Uint16(header) != SOI || header[2] != 0xff {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
 	return &JPEG{
-		bytes: data,
+		r: sr,
 	}, nil
 }
 
@@ -36,33 +58,229 @@ func (j *JPEG) MediaType() string {
 	return "image/jpeg"
 }
 
-func (j *JPEG) Exif() (*exif.Exif, error) {
-	offset := 2
+// segment is the position of a JPEG marker segment.
+type segment struct {
+	marker uint16
+	offset int64 // Offset of the segment data, after the length field
+	length int64 // Length of the segment data
+}
+
+// segments returns the marker segments preceding the image data. Reading
+// stops at the start of scan or at the first invalid segment.
+func (j *JPEG) segments() []segment {
+	var segments []segment
+
+	offset := int64(2)
 
 	for {
-		if offset+4 >= len(j.bytes) {
-			return nil, exif.ErrNoExifFound
+		header, err := fileformats.ReadAt(j.r, offset, 4)
+		if err != nil {
+			return segments
 		}
 
-		marker := binary.BigEndian.Uint16(j.bytes[offset:])
-		length := binary.BigEndian.Uint16(j.bytes[offset+2:])
+		marker := binary.BigEndian.Uint16(header)
+		length := int64(binary.BigEndian.Uint16(header[2:]))
 
-		if length == 0 {
-			return nil, exif.ErrNoExifFound
+		if marker == SOS || marker == EOI || length < 2 {
+			return segments
 		}
 
-		if marker == APP1 {
-			if offset+4+int(length) > len(j.bytes) {
-				return nil, exif.ErrNoExifFound
-			}
+		if offset+2+length > j.r.Size() {
+			return segments
+		}
+
+		segments = append(segments, segment{
+			marker: marker,
+			offset: offset + 4,
+			length: length - 2,
+		})
+
+		offset += length + 2
+	}
+}
+
+// appData returns the data following the signature of all segments
+// with the given marker starting with signature.
+func (j *JPEG) appData(marker uint16, signature string) [][]byte {
+	var payloads [][]byte
+
+	for _, s := range j.segments() {
+		if s.marker != marker || s.length < int64(len(signature)) {
+			continue
+		}
+
+		data, err := fileformats.ReadAt(j.r, s.offset, s.length)
+		if err != nil || string(data[:len(signature)]) != signature {
+			continue
+		}
+
+		payloads = append(payloads, data[len(signature):])
+	}
 
-			buf := j.bytes[offset+4:]
+	return payloads
+}
+
+func (j *JPEG) Exif() (*exif.Exif, error) {
+	for _, s := range j.segments() {
+		if s.marker != APP1 || s.length <= 6 {
+			continue
+		}
+
+		buf, err := fileformats.ReadAt(j.r, s.offset, 6)
+		if err == nil && string(buf) == "Exif\000\000" {
+			return exif.ParseReaderAt(io.NewSectionReader(j.r, s.offset+6, s.length-6), s.length-6)
+		}
+	}
+
+	return nil, exif.ErrNoExifFound
+}
+
+const (
+	xmpSignature         = "http://ns.adobe.com/xap/1.0/\000"
+	extendedXMPSignature = "http://ns.adobe.com/xmp/extension/\000"
+)
+
+// XMP returns the main XMP packet. If it holds an
+// xmpNote:HasExtendedXMP property, the remaining properties are found
+// in the packet returned by ExtendedXMP, which must be parsed
+// separately.
+func (j *JPEG) XMP() ([]byte, error) {
+	payloads := j.appData(APP1, xmpSignature)
+	if len(payloads) == 0 {
+		return nil, xmp.ErrNoXMPFound
+	}
+
+	return payloads[0], nil
+}
+
+// hasExtendedXMP matches the GUID of the extended XMP in the main
+// packet, either as attribute or element.
+var hasExtendedXMP = regexp.MustCompile(`HasExtendedXMP(?:="|>)([0-9A-Fa-f]{32})`)
+
+// ExtendedXMP returns the extended XMP packet reassembled from its
+// chunks. The packet referenced by xmpNote:HasExtendedXMP in the main
+// packet is used, or the only one present if there is no reference.
+func (j *JPEG) ExtendedXMP() ([]byte, error) {
+	// The chunk header is a 32 byte GUID, 4 bytes full length and 4
+	// bytes offset.
+	const headerSize = 40
+
+	var guid string
+
+	main, err := j.XMP()
+	if err == nil {
+		if m := hasExtendedXMP.FindSubmatch(main); m != nil {
+			guid = string(m[1])
+		}
+	}
 
-			if len(buf) >= 6 && string(buf[0:6]) == "Exif\000\000" {
-				return exif.Parse(buf[6:])
+	var packet []byte
+
+	for _, payload := range j.appData(APP1, extendedXMPSignature) {
+		if len(payload) < headerSize {
+			continue
+		}
+
+		if guid == "" {
+			guid = string(payload[:32])
+		}
+
+		if string(payload[:32]) != guid {
+			continue
+		}
+
+		fullLength := int64(binary.BigEndian.Uint32(payload[32:36]))
+		offset := int64(binary.BigEndian.Uint32(payload[36:40]))
+		chunk := payload[headerSize:]
+
+		if packet == nil {
+			if fullLength > j.r.Size() {
+				return nil, io.ErrUnexpectedEOF
 			}
+
+			packet = make([]byte, fullLength)
+		}
+
+		if offset+int64(len(chunk)) > int64(len(packet)) {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		copy(packet[offset:], chunk)
+	}
+
+	if packet == nil {
+		return nil, xmp.ErrNoXMPFound
+	}
+
+	return packet, nil
+}
+
+// ImageResources returns the Photoshop image resources from APP13.
+// Resources split across several segments are joined.
+func (j *JPEG) ImageResources() (psirb.Resources, error) {
+	payloads := j.appData(APP13, psirb.Signature)
+	if len(payloads) == 0 {
+		return nil, psirb.ErrNoResourcesFound
+	}
+
+	return psirb.Parse(bytes.Join(payloads, nil))
+}
+
+// IPTC returns the IPTC data from the Photoshop image resources.
+func (j *JPEG) IPTC() (*iptc.IPTC, error) {
+	resources, err := j.ImageResources()
+	if err != nil && len(resources) == 0 {
+		return nil, iptc.ErrNoIPTCFound
+	}
+
+	return resources.IPTC()
+}
+
+const iccSignature = "ICC_PROFILE\000"
+
+// ICCProfile returns the ICC profile reassembled from the APP2 chunks.
+// Each chunk carries its 1-based sequence number and the total number
+// of chunks. If no APP2 profile is found, the profile from the
+// Photoshop image resources is returned, if any.
+func (j *JPEG) ICCProfile() ([]byte, error) {
+	payloads := j.appData(APP2, iccSignature)
+
+	if len(payloads) == 0 {
+		resources, _ := j.ImageResources()
+
+		r, err := resources.Resource(psirb.ICCProfile)
+		if err != nil {
+			return nil, icc.ErrNoICCFound
+		}
+
+		return r.Data, nil
+	}
+
+	var chunks [][]byte
+
+	for _, payload := range payloads {
+		if len(payload) < 2 {
+			return nil, icc.ErrMalformed
+		}
+
+		seq, total := int(payload[0]), int(payload[1])
+
+		if chunks == nil {
+			chunks = make([][]byte, total)
 		}
 
-		offset += int(length) + 2
+		if seq < 1 || seq > len(chunks) || total != len(chunks) {
+			return nil, icc.ErrMalformed
+		}
+
+		chunks[seq-1] = payload[2:]
 	}
+
+	for _, c := range chunks {
+		if c == nil {
+			return nil, icc.ErrMalformed
+		}
+	}
+
+	return bytes.Join(chunks, nil), nil
 }
@@ -1,28 +1,53 @@
 package png
 
 import (
+	"bytes"
+	"compress/zlib"
 	"encoding/binary"
+	"errors"
+	"io"
 
 	"github.com/abrander/apexif/containers/exif"
+	"github.com/abrander/apexif/containers/icc"
+	"github.com/abrander/apexif/containers/xmp"
 	"github.com/abrander/apexif/fileformats"
 )
 
 type PNG struct {
-	bytes []byte
+	r *io.SectionReader
 }
 
 const signature string = "\x89PNG\r\n\x1a\n"
 const crcSize = 4
 
+// maxInflatedSize limits the size of compressed chunk data after
+// decompression, to guard against zlib bombs.
+const maxInflatedSize = 64 << 20
+
+// ErrInflatedTooLarge is returned if compressed chunk data exceeds
+// maxInflatedSize when decompressed.
+var ErrInflatedTooLarge = errors.New("decompressed chunk data too large")
+
 var _ fileformats.FileType = &PNG{}
 
 func Identify(data []byte) (fileformats.FileType, error) {
-	if len(data) < 2*len(signature) || string(data[:len(signature)]) != signature {
+	return IdentifyReaderAt(bytes.NewReader(data), int64(len(data)))
+}
+
+func IdentifyReaderAt(r io.ReaderAt, size int64) (fileformats.FileType, error) {
+	if size < 2*int64(len(signature)) {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	sr := io.NewSectionReader(r, 0, size)
+
+	header, err := fileformats.ReadAt(sr, 0, int64(len(signature)))
+	if err != nil || string(header) != signature {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
 	return &PNG{
-		bytes: data,
+		r: sr,
 	}, nil
 }
 
@@ -34,29 +59,138 @@ func (p *PNG) MediaType() string {
 	return "image/png"
 }
 
-func (p *PNG) Exif() (*exif.Exif, error) {
-	offset := len(signature)
+// chunk is the position of a PNG chunk.
+type chunk struct {
+	chunkType string
+	offset    int64 // Offset of the chunk data
+	length    int64
+}
+
+// chunks returns the chunks up to and including IEND. Reading stops
+// at the first chunk that doesn't fit in the file.
+func (p *PNG) chunks() []chunk {
+	var chunks []chunk
+
+	offset := int64(len(signature))
 
 	for {
-		if offset >= len(p.bytes) {
-			return nil, exif.ErrNoExifFound
+		header, err := fileformats.ReadAt(p.r, offset, 8)
+		if err != nil {
+			return chunks
 		}
 
-		if offset+8 > len(p.bytes) {
-			return nil, exif.ErrNoExifFound
+		length := int64(binary.BigEndian.Uint32(header))
+		chunkType := string(header[4:8])
+
+		offset += 8
+
+		if offset+length > p.r.Size() {
+			return chunks
 		}
 
-		length := binary.BigEndian.Uint32(p.bytes[offset:])
-		chunkType := string(p.bytes[offset+4 : offset+8])
+		chunks = append(chunks, chunk{chunkType: chunkType, offset: offset, length: length})
 
-		offset += 8
+		if chunkType == "IEND" {
+			return chunks
+		}
 
-		switch chunkType {
-		case "eXIf":
-			return exif.Parse(p.bytes[offset : offset+int(length)])
+		offset += length + crcSize
+	}
+}
 
-		default:
-			offset += int(length) + crcSize
+func (p *PNG) Exif() (*exif.Exif, error) {
+	for _, c := range p.chunks() {
+		if c.chunkType == "eXIf" {
+			return exif.ParseReaderAt(io.NewSectionReader(p.r, c.offset, c.length), c.length)
 		}
 	}
+
+	return nil, exif.ErrNoExifFound
+}
+
+// xmpKeyword is the iTXt keyword used for XMP packets.
+const xmpKeyword = "XML:com.adobe.xmp"
+
+func (p *PNG) XMP() ([]byte, error) {
+	for _, c := range p.chunks() {
+		if c.chunkType != "iTXt" {
+			continue
+		}
+
+		data, err := fileformats.ReadAt(p.r, c.offset, c.length)
+		if err != nil {
+			continue
+		}
+
+		keyword, rest, found := bytes.Cut(data, []byte{0})
+		if !found || string(keyword) != xmpKeyword || len(rest) < 2 {
+			continue
+		}
+
+		compressed := rest[0] == 1
+
+		// Skip compression flag and method, language tag and
+		// translated keyword.
+		_, rest, found = bytes.Cut(rest[2:], []byte{0})
+		if !found {
+			continue
+		}
+
+		_, text, found := bytes.Cut(rest, []byte{0})
+		if !found {
+			continue
+		}
+
+		if !compressed {
+			return text, nil
+		}
+
+		return inflate(text)
+	}
+
+	return nil, xmp.ErrNoXMPFound
+}
+
+func (p *PNG) ICCProfile() ([]byte, error) {
+	for _, c := range p.chunks() {
+		if c.chunkType != "iCCP" {
+			continue
+		}
+
+		data, err := fileformats.ReadAt(p.r, c.offset, c.length)
+		if err != nil {
+			continue
+		}
+
+		// The profile name is followed by the compression method,
+		// which is always zlib.
+		_, rest, found := bytes.Cut(data, []byte{0})
+		if !found || len(rest) < 1 {
+			return nil, icc.ErrNoICCFound
+		}
+
+		return inflate(rest[1:])
+	}
+
+	return nil, icc.ErrNoICCFound
+}
+
+// inflate decompresses zlib compressed chunk data.
+func inflate(data []byte) ([]byte, error) {
+	z, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+
+	buf, err := io.ReadAll(io.LimitReader(z, maxInflatedSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(buf) > maxInflatedSize {
+		return nil, ErrInflatedTooLarge
+	}
+
+	return buf, nil
 }
@@ -1,18 +1,33 @@
 package tif
 
 import (
+	"bytes"
 	"encoding/binary"
+	"io"
 
 	"github.com/abrander/apexif/containers/exif"
+	"github.com/abrander/apexif/containers/icc"
+	"github.com/abrander/apexif/containers/xmp"
 	"github.com/abrander/apexif/fileformats"
 )
 
 type Tif struct {
-	bytes []byte
+	r *io.SectionReader
 }
 
 func Identify(data []byte) (fileformats.FileType, error) {
-	if len(data) < 8 {
+	return IdentifyReaderAt(bytes.NewReader(data), int64(len(data)))
+}
+
+func IdentifyReaderAt(r io.ReaderAt, size int64) (fileformats.FileType, error) {
+	if size < 8 {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	sr := io.NewSectionReader(r, 0, size)
+
+	data, err := fileformats.ReadAt(sr, 0, 4)
+	if err != nil {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
@@ -29,13 +44,14 @@ func Identify(data []byte) (fileformats.FileType, error) {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
+	// 42 is classic TIFF, 43 BigTIFF.
 	magic := endianness.Uint16(data[2:])
-	if magic != 42 {
+	if magic != 42 && magic != 43 {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
 	return &Tif{
-		bytes: data,
+		r: sr,
 	}, nil
 }
 
@@ -48,5 +64,34 @@ func (t *Tif) MediaType() string {
 }
 
 func (t *Tif) Exif() (*exif.Exif, error) {
-	return exif.Parse(t.bytes)
+	return exif.ParseReaderAt(t.r, t.r.Size())
+}
+
+func (t *Tif) XMP() ([]byte, error) {
+	e, err := t.Exif()
+	if err != nil {
+		return nil, xmp.ErrNoXMPFound
+	}
+
+	return e.XMP()
+}
+
+func (t *Tif) ICCProfile() ([]byte, error) {
+	e, err := t.Exif()
+	if err != nil {
+		return nil, icc.ErrNoICCFound
+	}
+
+	return e.ICCProfile()
+}
+
+// Preview returns the largest JPEG preview from the SubIFDs, as found
+// in DNG and NEF files.
+func (t *Tif) Preview() ([]byte, error) {
+	e, err := t.Exif()
+	if err != nil {
+		return nil, err
+	}
+
+	return e.Preview()
 }
@@ -1,30 +1,47 @@
 package webp
 
 import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
 	"github.com/abrander/apexif/containers/exif"
+	"github.com/abrander/apexif/containers/icc"
 	"github.com/abrander/apexif/containers/riff"
+	"github.com/abrander/apexif/containers/xmp"
 	"github.com/abrander/apexif/fileformats"
 )
 
 type Webp struct {
-	bytes []byte
+	r *io.SectionReader
 }
 
 func Identify(data []byte) (fileformats.FileType, error) {
-	if len(data) < 12 {
+	return IdentifyReaderAt(bytes.NewReader(data), int64(len(data)))
+}
+
+func IdentifyReaderAt(r io.ReaderAt, size int64) (fileformats.FileType, error) {
+	if size < 12 {
+		return nil, fileformats.ErrImageNotRecognized
+	}
+
+	sr := io.NewSectionReader(r, 0, size)
+
+	header, err := fileformats.ReadAt(sr, 0, 12)
+	if err != nil {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
-	if string(data[:4]) != "RIFF" {
+	if string(header[:4]) != "RIFF" {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
-	if string(data[8:12]) != "WEBP" {
+	if string(header[8:12]) != "WEBP" {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
 	return &Webp{
-		bytes: data,
+		r: sr,
 	}, nil
 }
 
@@ -36,26 +53,76 @@ func (w *Webp) MediaType() string {
 	return "image/webp"
 }
 
-func (w *Webp) Exif() (*exif.Exif, error) {
-	r, err := riff.Parse(w.bytes)
+// chunks returns the headers of the chunks inside the RIFF chunk.
+func (w *Webp) chunks() ([]riff.ChunkHeader, error) {
+	header, err := fileformats.ReadAt(w.r, 0, 12)
 	if err != nil {
 		return nil, err
 	}
 
-	if r.Riff.Length < 10 {
+	length := int64(binary.LittleEndian.Uint32(header[4:8]))
+	if length < 10 {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
-	if string(r.Riff.Data[:4]) != "WEBP" {
+	if 8+length > w.r.Size() {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	if string(header[8:12]) != "WEBP" {
 		return nil, fileformats.ErrImageNotRecognized
 	}
 
-	chunks, _ := riff.ReadChunks(r.Riff.Data[4:])
+	// A truncated chunk ends the list, but the chunks before it are
+	// still usable.
+	chunks, _ := riff.ReadChunkHeaders(w.r, 12, 8+length)
+
+	return chunks, nil
+}
+
+func (w *Webp) Exif() (*exif.Exif, error) {
+	chunks, err := w.chunks()
+	if err != nil {
+		return nil, err
+	}
+
 	for _, chunk := range chunks {
 		if chunk.Identifier == "EXIF" && chunk.Length > 6 {
-			return exif.Parse(chunk.Data[6:])
+			length := int64(chunk.Length) - 6
+
+			return exif.ParseReaderAt(io.NewSectionReader(w.r, chunk.Offset+6, length), length)
 		}
 	}
 
 	return nil, exif.ErrNoExifFound
 }
+
+func (w *Webp) XMP() ([]byte, error) {
+	chunks, err := w.chunks()
+	if err != nil {
+		return nil, xmp.ErrNoXMPFound
+	}
+
+	for _, chunk := range chunks {
+		if chunk.Identifier == "XMP " {
+			return chunk.Data(w.r)
+		}
+	}
+
+	return nil, xmp.ErrNoXMPFound
+}
+
+func (w *Webp) ICCProfile() ([]byte, error) {
+	chunks, err := w.chunks()
+	if err != nil {
+		return nil, icc.ErrNoICCFound
+	}
+
+	for _, chunk := range chunks {
+		if chunk.Identifier == "ICCP" {
+			return chunk.Data(w.r)
+		}
+	}
+
+	return nil, icc.ErrNoICCFound
+}